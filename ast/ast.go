@@ -1,23 +1,116 @@
+// Package ast provides a generic node type for the results produced by parsers,
+// along with helpers to walk and rewrite trees of those nodes.
 package ast
 
+import (
+	"github.com/gdey/ppc/parse"
+)
+
+// Span is the range of bytes, [Start, End), that a node was parsed from
+type Span struct {
+	Start int64
+	End   int64
+}
+
+// Len is the number of bytes covered by the span
+func (s Span) Len() int64 { return s.End - s.Start }
+
+// Contains reports wether the offset is within the span
+func (s Span) Contains(offset int64) bool { return s.Start <= offset && offset < s.End }
+
+// Node is any element of a parsed tree
+type Node interface {
+	// Span is where in the source the node came from
+	Span() Span
+	// Children are the direct sub nodes of this node, in source order.
+	// Leaf nodes return nil.
+	Children() []Node
+}
+
+// Parent is a Node whose children can be replaced; it's used by Rewrite
+type Parent interface {
+	Node
+	// WithChildren returns a copy of the node with the given children
+	// Children removed by Rewrite will not be in the list
+	WithChildren(children []Node) Node
+}
+
+// Token is a leaf node with a name and the result of the parser that matched it
 type Token struct {
-	Name string
+	Name  string
 	Value interface{}
+	Start int64
+	End   int64
 }
 
+func (t Token) Span() Span       { return Span{Start: t.Start, End: t.End} }
+func (t Token) Children() []Node { return nil }
+
+// TokenKeyword is a leaf node where the matched text does not matter only that it matched
 type TokenKeyword struct {
-	Name string
+	Name  string
+	Start int64
+	End   int64
 }
 
+func (t TokenKeyword) Span() Span       { return Span{Start: t.Start, End: t.End} }
+func (t TokenKeyword) Children() []Node { return nil }
 
-func Keyword(name string, parser parser.Parserer) parser.ParseFunc {
-	tok := TokenKeyword{Name:name}
-	return parser.ParseFunc(func(state parser.State) parser.State{
+// Keyword will replace the result of the parser with a TokenKeyword
+// result is TokenKeyword
+func Keyword(name string, parser parse.Parser) parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
 		next := parser.Run(state)
 		if next.IsError {
 			return next
 		}
 		// modify the result.
-		return next.WithResult(tok,next.Index)
+		return next.WithResult(
+			TokenKeyword{
+				Name:  name,
+				Start: state.Index,
+				End:   next.Index,
+			},
+			next.Index,
+		)
 	})
 }
+
+// Tok will wrap the result of the parser in a Token
+// result is Token
+func Tok(name string, parser parse.Parser) parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		next := parser.Run(state)
+		if next.IsError {
+			return next
+		}
+		return next.WithResult(
+			Token{
+				Name:  name,
+				Value: next.Result,
+				Start: state.Index,
+				End:   next.Index,
+			},
+			next.Index,
+		)
+	})
+}
+
+// Nodes will collect the Nodes in a result, such as the one returned by parse.SequenceOf or
+// parse.Many, dropping anything that is not a Node
+func Nodes(r interface{}) []Node {
+	switch result := r.(type) {
+	case Node:
+		return []Node{result}
+	case []Node:
+		return result
+	case []interface{}:
+		var nodes []Node
+		for i := range result {
+			nodes = append(nodes, Nodes(result[i])...)
+		}
+		return nodes
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,211 @@
+package ast
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gdey/ppc/parse"
+	"github.com/gdey/ppc/parse/match"
+)
+
+// tree is a Parent for the tests
+type tree struct {
+	name string
+	span Span
+	kids []Node
+}
+
+func (t tree) Span() Span       { return t.span }
+func (t tree) Children() []Node { return t.kids }
+func (t tree) WithChildren(children []Node) Node {
+	t.kids = children
+	return t
+}
+
+// leaf is a Node that is not a Parent
+type leaf struct {
+	name string
+	kids []Node
+}
+
+func (l leaf) Span() Span       { return Span{} }
+func (l leaf) Children() []Node { return l.kids }
+
+func name(node Node) string {
+	switch node := node.(type) {
+	case tree:
+		return node.name
+	case leaf:
+		return node.name
+	case Token:
+		return node.Name
+	case TokenKeyword:
+		return node.Name
+	case nil:
+		return "nil"
+	}
+	return "?"
+}
+
+// testTree is
+//
+//	root
+//	├── a
+//	│   ├── a1
+//	│   └── a2
+//	└── b
+var testTree = tree{name: "root", kids: []Node{
+	tree{name: "a", kids: []Node{Token{Name: "a1"}, nil, Token{Name: "a2"}}},
+	TokenKeyword{Name: "b"},
+}}
+
+func TestSpan(t *testing.T) {
+	span := Span{Start: 2, End: 5}
+	if span.Len() != 3 {
+		t.Errorf("expected len 3 got %v", span.Len())
+	}
+	for offset, expected := range map[int64]bool{1: false, 2: true, 4: true, 5: false} {
+		if span.Contains(offset) != expected {
+			t.Errorf("contains %v, expected %v", offset, expected)
+		}
+	}
+	if (Span{Start: 3, End: 3}).Contains(3) {
+		t.Errorf("expected an empty span to contain nothing")
+	}
+}
+
+func TestTok(t *testing.T) {
+	parser := parse.SequenceOf(
+		Keyword("let", match.String("let")),
+		match.String(" "),
+		Tok("name", match.String("abc")),
+	)
+	state := parse.String(parser, "let abc")
+	if state.IsError {
+		t.Fatalf("unexpected error: %v", state.Err)
+	}
+	results := state.Result.([]interface{})
+	if kw := (TokenKeyword{Name: "let", Start: 0, End: 3}); results[0] != kw {
+		t.Errorf("expected %#v got %#v", kw, results[0])
+	}
+	tok := results[2].(Token)
+	if tok.Name != "name" || tok.Value != "abc" || tok.Span() != (Span{Start: 4, End: 7}) || tok.Children() != nil {
+		t.Errorf("expected name abc at 4-7 got %#v", tok)
+	}
+
+	if state := parse.String(Tok("name", match.String("abc")), "123"); !state.IsError {
+		t.Errorf("expected error, got %#v", state.Result)
+	}
+	if state := parse.String(Keyword("let", match.String("let")), "var"); !state.IsError {
+		t.Errorf("expected error, got %#v", state.Result)
+	}
+}
+
+func TestNodes(t *testing.T) {
+	a, b, c := Token{Name: "a"}, TokenKeyword{Name: "b"}, Token{Name: "c"}
+	tests := map[string]struct {
+		result interface{}
+		nodes  []Node
+	}{
+		"node":     {result: a, nodes: []Node{a}},
+		"nodes":    {result: []Node{a, b}, nodes: []Node{a, b}},
+		"nested":   {result: []interface{}{a, "x", []interface{}{b, nil, []Node{c}}}, nodes: []Node{a, b, c}},
+		"no nodes": {result: []interface{}{"x", 1}, nodes: nil},
+		"other":    {result: "x", nodes: nil},
+	}
+	for n, tc := range tests {
+		t.Run(n, func(t *testing.T) {
+			if nodes := Nodes(tc.result); !reflect.DeepEqual(nodes, tc.nodes) {
+				t.Errorf("expected %v got %v", tc.nodes, nodes)
+			}
+		})
+	}
+}
+
+type recorder []string
+
+func (r *recorder) Visit(node Node) Visitor {
+	*r = append(*r, name(node))
+	if name(node) == "skip" {
+		return nil
+	}
+	return r
+}
+
+func TestWalk(t *testing.T) {
+	var r recorder
+	Walk(&r, testTree)
+	expected := []string{"root", "a", "a1", "nil", "a2", "nil", "nil", "b", "nil", "nil"}
+	if !reflect.DeepEqual([]string(r), expected) {
+		t.Errorf("expected %v got %v", expected, r)
+	}
+
+	// the children of a skipped node are not visited, and there is no nil for it
+	r = nil
+	Walk(&r, tree{name: "root", kids: []Node{tree{name: "skip", kids: []Node{Token{Name: "x"}}}, Token{Name: "y"}}})
+	expected = []string{"root", "skip", "y", "nil", "nil"}
+	if !reflect.DeepEqual([]string(r), expected) {
+		t.Errorf("expected %v got %v", expected, r)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	var names []string
+	Inspect(testTree, func(node Node) bool {
+		names = append(names, name(node))
+		return name(node) != "a"
+	})
+	expected := []string{"root", "a", "b", "nil", "nil"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v got %v", expected, names)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	var order []string
+	result := Rewrite(testTree, func(node Node) Node {
+		order = append(order, name(node))
+		switch node := node.(type) {
+		case Token:
+			if node.Name == "a2" {
+				return nil
+			}
+			node.Name = strings.ToUpper(node.Name)
+			return node
+		case tree:
+			// the children have been rewritten already
+			var names []string
+			for _, kid := range node.kids {
+				names = append(names, name(kid))
+			}
+			node.name += "(" + strings.Join(names, ",") + ")"
+			return node
+		}
+		return node
+	})
+	if expected := []string{"a1", "a2", "a", "b", "root"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("order, expected %v got %v", expected, order)
+	}
+	if got, expected := name(result), "root(a(A1),b)"; got != expected {
+		t.Errorf("expected %v got %v", expected, got)
+	}
+	// the original is not changed
+	if len(testTree.kids[0].Children()) != 3 {
+		t.Errorf("expected the original tree to keep its children")
+	}
+
+	// the children of a node that is not a Parent are visited, but the node keeps them
+	var visited []string
+	node := leaf{name: "leaf", kids: []Node{Token{Name: "x"}}}
+	result = Rewrite(node, func(node Node) Node {
+		visited = append(visited, name(node))
+		return nil
+	})
+	if result != nil || !reflect.DeepEqual(visited, []string{"x", "leaf"}) {
+		t.Errorf("expected x and leaf to be visited, got %v (%v)", visited, result)
+	}
+	if Rewrite(nil, func(node Node) Node { return node }) != nil {
+		t.Errorf("expected nil for a nil node")
+	}
+}
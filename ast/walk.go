@@ -0,0 +1,66 @@
+package ast
+
+// Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the tree in depth-first order starting with node
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	for _, child := range node.Children() {
+		if child == nil {
+			continue
+		}
+		Walk(v, child)
+	}
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree in depth-first order calling fn for each node.
+// If fn returns false the children of that node are skipped.
+// After the children of a node are visited fn is called with nil.
+func Inspect(node Node, fn func(Node) bool) {
+	Walk(inspector(fn), node)
+}
+
+// Rewrite traverses the tree in depth-first order, replacing each node with
+// the value returned by fn. Children are rewritten before their parent, so fn
+// sees the parent with its children already rewritten.
+// Only nodes that implement Parent can have their children replaced; for other
+// nodes the children are still visited but the results are dropped.
+// If fn returns nil for a child, the child is removed.
+func Rewrite(node Node, fn func(Node) Node) Node {
+	if node == nil {
+		return nil
+	}
+	children := node.Children()
+	if len(children) > 0 {
+		newChildren := make([]Node, 0, len(children))
+		for _, child := range children {
+			if child == nil {
+				continue
+			}
+			if nc := Rewrite(child, fn); nc != nil {
+				newChildren = append(newChildren, nc)
+			}
+		}
+		if p, ok := node.(Parent); ok {
+			node = p.WithChildren(newChildren)
+		}
+	}
+	return fn(node)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gdey/ppc/lang/gdtxt"
+	"github.com/gdey/ppc/lang/gdtxt/html"
+)
+
+// gdhtml renders a gdtxt file, and any files it inserts, as a HTML page
+//
+//	go run ./cmd/examples/gdhtml notes.gdtxt > notes.html
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: %v file.gdtxt\n", os.Args[0])
+		os.Exit(2)
+	}
+	doc, err := gdtxt.Inserter{Resolver: gdtxt.FileResolver{}}.Document(os.Args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := (html.Renderer{}).Render(os.Stdout, doc); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
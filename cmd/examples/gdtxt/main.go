@@ -7,29 +7,6 @@ import (
 	"github.com/gdey/ppc/parse"
 )
 
-type tagged struct {
-	Type   string
-	Result interface{}
-	Index  int64
-}
-
-func Tagged(parser parse.Parser, tag string) parse.Parser {
-	return parse.Func(func(state parse.State) parse.State {
-		nextState := parser.Run(state)
-		if nextState.IsError {
-			return nextState
-		}
-		return nextState.WithResult(
-			tagged{
-				Type:   tag,
-				Result: nextState.Result,
-				Index:  nextState.Index,
-			},
-			nextState.Index,
-		)
-	})
-}
-
 func main() {
 
 	const corpus = `«
@@ -80,21 +57,25 @@ This is the \[ this is a parental \] body of the text
 This is the [* second *] of the text.
 `
 
-	result := parse.String(
-		parse.Many(
-			parse.ChoiceOf(
-				gdtxt.ParseBlock,
-				gdtxt.ParseLineTypes,
-			),
-		),
-		corpus,
-	)
-
-	fmt.Println(corpus)
+	result := parse.String(gdtxt.ParseDocument, corpus)
+
+	fmt.Print(corpus, "\n")
 	if result.IsError {
 		fmt.Printf("Got Error: %v\n", result.Err.Error())
-	} else {
-		fmt.Printf("Result:\n%#v\n", result.Result)
+		return
 	}
+	doc := result.Result.(*gdtxt.Document)
+	fmt.Printf("Result:\nFront matter: %#v\n", doc.FrontMatter)
+	printChildren("", doc.Elements)
+}
 
+func printChildren(indent string, children []interface{}) {
+	for _, child := range children {
+		if section, ok := child.(*gdtxt.Section); ok {
+			fmt.Printf("%v%#v\n", indent, section.SectionLine)
+			printChildren(indent+"\t", section.Elements)
+			continue
+		}
+		fmt.Printf("%v%#v\n", indent, child)
+	}
 }
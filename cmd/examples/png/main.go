@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"os"
+
+	"github.com/gdey/ppc/parse"
+	"github.com/gdey/ppc/parse/binary"
+)
+
+type chunk struct {
+	Type   string
+	Length int
+	CRC    uint32
+	Index  int64
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+var parseChunk = parse.MapIndex(
+	parse.Chain(
+		binary.Uint32BE(),
+		func(length interface{}) parse.Parser {
+			return parse.SequenceOf(
+				binary.Bytes(4),
+				binary.Bytes(int(length.(uint32))),
+				binary.Uint32BE(),
+			)
+		},
+	),
+	func(r interface{}, idx int64) interface{} {
+		results := r.([]interface{})
+		return chunk{
+			Type:   string(results[0].([]byte)),
+			Length: len(results[1].([]byte)),
+			CRC:    results[2].(uint32),
+			Index:  idx,
+		}
+	},
+)
+
+var parsePNG = parse.Map(
+	parse.SequenceOf(
+		binary.Magic(pngSignature),
+		parse.Many1(parseChunk),
+		parse.EndOfInput(),
+	),
+	func(r interface{}) interface{} {
+		return r.([]interface{})[1]
+	},
+)
+
+func main() {
+	var result parse.State
+	if len(os.Args) > 1 {
+		var err error
+		result, err = parse.File(parsePNG, os.Args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		// Make a small png to parse
+		img := image.NewGray(image.Rect(0, 0, 4, 4))
+		img.Set(1, 1, color.White)
+		var buff bytes.Buffer
+		if err := png.Encode(&buff, img); err != nil {
+			log.Fatal(err)
+		}
+		result = parse.Bytes(parsePNG, buff.Bytes())
+	}
+
+	if result.IsError {
+		fmt.Printf("Got Error: %v\n", result.Err.Error())
+		return
+	}
+	for _, c := range result.Result.([]interface{}) {
+		fmt.Printf("%#v\n", c)
+	}
+}
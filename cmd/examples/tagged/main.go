@@ -10,29 +10,6 @@ import (
 	"github.com/gdey/ppc/parse/match"
 )
 
-type tagged struct {
-	Type   string
-	Result interface{}
-	Index  int64
-}
-
-func Tagged(parser parse.Parser, tag string) parse.Parser {
-	return parse.Func(func(state parse.State) parse.State {
-		nextState := parser.Run(state)
-		if nextState.IsError {
-			return nextState
-		}
-		return nextState.WithResult(
-			tagged{
-				Type:   tag,
-				Result: nextState.Result,
-				Index:  nextState.Index,
-			},
-			nextState.Index,
-		)
-	})
-}
-
 func main() {
 
 	quotedString := parse.Between(
@@ -62,11 +39,11 @@ func main() {
 	)
 	_ = quotedString
 	spaceMatcher := match.Rune(unicode.IsSpace, errors.New("unable to match space"))
-	selectMatcher := Tagged(match.StringInsensitive("select"), "SELECT")
+	selectMatcher := parse.Tagged(match.Keywords(true, "select"), "SELECT")
 
 	ourParser := parse.SequenceOf(
 		selectMatcher,
-		Tagged(parse.Many(spaceMatcher), "WHITE-SPACE"),
+		parse.Tagged(parse.Many(spaceMatcher), "WHITE-SPACE"),
 		parse.Many(
 			parse.Map(
 				parse.SequenceOf(
@@ -1,10 +1,290 @@
 /*
-Package escapedString allows one to parse escaped strings which is a string surrounded by `"` and has 
+Package escapedString allows one to parse escaped strings which is a string surrounded by `"` and has
 a `\` to escape characters:
 \" -> "
 \n -> newline
 \\ -> \
-\ua2f3 -> unicode character where the hex number is the utf8 code point
+\t -> tab
+\r -> carriage return
+\xHH -> unicode character where the 2 digit hex number is the code point (U+0000 to U+00FF)
+\ua2f3 -> unicode character where the 4 digit hex number is the code point
+\u{1f600} -> unicode character where the 1 to 6 digit hex number is the code point
+
+A UTF-16 surrogate pair written as two \u escapes (\ud83d\ude00) is decoded as the one character;
+a lone surrogate is an error. A newline in the string has to be escaped.
+
+	state := parse.String(escapedString.Parser, `"café \u{1f600}\n"`)
+	// state.Result is "café 😀\n"
+
+Encode does the reverse, and Decode parses a string that holds just the escaped string.
+Decode(Encode(s)) gives back s for any valid UTF-8 string. Invalid UTF-8 does not survive the
+round trip: \xHH is a code point and not a byte, so each invalid byte is encoded as \ufffd.
+*/
 package escapedString
 
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/gdey/ppc/parse"
+)
+
+// Error is returned for an invalid escape sequence
+type Error struct {
+	// Offset is the offset of the `\` that starts the escape
+	Offset int64
+	// Escape is the text of the escape, as far as it was read
+	Escape string
+	Reason string
+}
+
+func (err Error) Error() string {
+	return fmt.Sprintf("invalid escape %q at %v: %v", err.Escape, err.Offset, err.Reason)
+}
+
+var (
+	// ErrUnterminated is returned when the end of input is reached before the closing `"`
+	ErrUnterminated = errors.New("unterminated string")
+	// ErrNewline is returned when there is an unescaped newline in the string
+	ErrNewline = errors.New("newline in string")
+)
+
+// Parser matches a double quoted escaped string
+// result is the decoded string
+var Parser = parse.Func(func(state parse.State) parse.State {
+	r, n, err := state.ReadNextRune()
+	if err != nil || r != '"' {
+		if parse.IsInvalidUTF8(err) {
+			return state.WithError(err)
+		}
+		return state.WithError(fmt.Errorf("expected '\"' at %v", state.Index))
+	}
+	var (
+		runes  []rune
+		cstate = state
+	)
+	cstate.Index += int64(n)
+	for {
+		r, n, err := cstate.ReadNextRune()
+		switch {
+		case parse.IsInvalidUTF8(err):
+			return state.WithError(err)
+		case err != nil:
+			return state.WithError(fmt.Errorf("%w starting at %v", ErrUnterminated, state.Index))
+		case r == '"':
+			return state.WithResult(parse.RunesToString(runes), cstate.Index+int64(n))
+		case parse.IsNewline(r):
+			return state.WithError(fmt.Errorf("%w at %v", ErrNewline, cstate.Index))
+		case r == '\\':
+			er, next, err := readEscape(cstate)
+			if err != nil {
+				return state.WithError(err)
+			}
+			runes = append(runes, er)
+			cstate = next
+			continue
+		}
+		runes = append(runes, r)
+		cstate.Index += int64(n)
+	}
+})
+
+// readEscape reads the escape starting at the `\` at the state's index
+func readEscape(state parse.State) (rune, parse.State, error) {
+	cstate := state
+	cstate.Index++ // skip the `\`
+	escape := func(reason string) error {
+		text := make([]byte, cstate.Index-state.Index)
+		n, _ := state.Source.ReadAt(text, state.Index)
+		return Error{Offset: state.Index, Escape: string(text[:n]), Reason: reason}
+	}
+	r, n, err := cstate.ReadNextRune()
+	if err != nil {
+		if parse.IsInvalidUTF8(err) {
+			return 0, state, err
+		}
+		return 0, state, escape("unexpected end of input")
+	}
+	cstate.Index += int64(n)
+	switch r {
+	case '"', '\\':
+		return r, cstate, nil
+	case 'n':
+		return '\n', cstate, nil
+	case 't':
+		return '\t', cstate, nil
+	case 'r':
+		return '\r', cstate, nil
+	case 'x':
+		v, ok := readHex(&cstate, 2)
+		if !ok {
+			return 0, state, escape(`\x must be followed by 2 hex digits`)
+		}
+		return v, cstate, nil
+	case 'u':
+	default:
+		return 0, state, escape("unknown escape")
+	}
+
+	v, err := readCodePoint(&cstate)
+	if err != nil {
+		return 0, state, escape(err.Error())
+	}
+	if !utf16.IsSurrogate(v) {
+		return v, cstate, nil
+	}
+	if v >= 0xDC00 {
+		return 0, state, escape("unexpected low surrogate")
+	}
+	// a high surrogate has to be followed by an escaped low surrogate
+	if buff, n, _ := cstate.ReadNextBytes(2); n != 2 || string(buff) != `\u` {
+		return 0, state, escape("high surrogate without a low surrogate")
+	}
+	cstate.Index += 2
+	low, err := readCodePoint(&cstate)
+	if err != nil {
+		return 0, state, escape(err.Error())
+	}
+	pair := utf16.DecodeRune(v, low)
+	if pair == unicode.ReplacementChar {
+		return 0, state, escape("high surrogate without a low surrogate")
+	}
+	return pair, cstate, nil
+}
+
+// readCodePoint reads the XXXX or {X...} after a \u
+func readCodePoint(state *parse.State) (rune, error) {
+	buff, n, _ := state.ReadNextBytes(1)
+	if n != 1 || buff[0] != '{' {
+		v, ok := readHex(state, 4)
+		if !ok {
+			return 0, errors.New(`\u must be followed by 4 hex digits`)
+		}
+		return v, nil
+	}
+	state.Index++
+	var (
+		v      rune
+		digits int
+	)
+	for {
+		buff, n, _ := state.ReadNextBytes(1)
+		if n != 1 {
+			return 0, errors.New("unterminated \\u{")
+		}
+		state.Index++
+		if buff[0] == '}' {
+			break
+		}
+		d, ok := hexDigit(buff[0])
+		if !ok {
+			return 0, errors.New(`\u{ must contain only hex digits`)
+		}
+		digits++
+		if digits > 6 {
+			return 0, errors.New(`\u{ can have at most 6 hex digits`)
+		}
+		v = v<<4 | d
+	}
+	switch {
+	case digits == 0:
+		return 0, errors.New(`\u{ must contain at least one hex digit`)
+	case v > unicode.MaxRune:
+		return 0, errors.New("code point out of range")
+	case utf16.IsSurrogate(v):
+		return 0, errors.New(`surrogate code points are not allowed in \u{`)
+	}
+	return v, nil
+}
+
+// readHex reads exactly n hex digits
+func readHex(state *parse.State, n int) (rune, bool) {
+	buff, nn, _ := state.ReadNextBytes(n)
+	if nn != n {
+		return 0, false
+	}
+	var v rune
+	for _, b := range buff {
+		d, ok := hexDigit(b)
+		if !ok {
+			return 0, false
+		}
+		v = v<<4 | d
+	}
+	state.Index += int64(n)
+	return v, true
+}
+
+func hexDigit(b byte) (rune, bool) {
+	switch {
+	case '0' <= b && b <= '9':
+		return rune(b - '0'), true
+	case 'a' <= b && b <= 'f':
+		return rune(b-'a') + 10, true
+	case 'A' <= b && b <= 'F':
+		return rune(b-'A') + 10, true
+	}
+	return 0, false
+}
+
+// Decode will decode s, which must be just an escaped string (including the quotes)
+func Decode(s string) (string, error) {
+	state := parse.String(parse.SequenceOf(Parser, parse.EndOfInput()), s)
+	if state.IsError {
+		return "", state.Err
+	}
+	return state.Result.([]interface{})[0].(string), nil
+}
+
+// Encode returns s as an escaped string, surrounded by `"`, that Parser will decode back to s
+// if s is valid UTF-8.
+// Control and other non printable characters are written as \xHH, \uXXXX or \u{XXXXX}.
+// Invalid UTF-8 can not be represented and each invalid byte is written as \ufffd, so
+// Encode("\xff") is `"\ufffd"` and decodes to "\uFFFD" rather than "\xff".
+func Encode(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"':
+			b.WriteString(`\"`)
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == utf8.RuneError:
+			if _, n := utf8.DecodeRuneInString(s[i:]); n == 1 {
+				b.WriteString(`\ufffd`)
+				continue
+			}
+			b.WriteRune(r)
+		case r < 0x100 && !unicode.IsPrint(r):
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case !unicode.IsPrint(r):
+			if r > 0xFFFF {
+				fmt.Fprintf(&b, `\u{%x}`, r)
+				continue
+			}
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
 
+// Write writes s as an escaped string to w
+func Write(w io.Writer, s string) (int, error) {
+	return io.WriteString(w, Encode(s))
+}
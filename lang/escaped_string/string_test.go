@@ -0,0 +1,158 @@
+package escapedString
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gdey/ppc/parse"
+	"github.com/gdey/ppc/parse/match"
+)
+
+func TestDecode(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected string
+	}{
+		"plain":           {input: `"café"`, expected: "café"},
+		"empty":           {input: `""`, expected: ""},
+		"quote":           {input: `"a\"b"`, expected: `a"b`},
+		"backslash":       {input: `"a\\b"`, expected: `a\b`},
+		"newline":         {input: `"a\nb"`, expected: "a\nb"},
+		"tab":             {input: `"a\tb"`, expected: "a\tb"},
+		"carriage return": {input: `"a\rb"`, expected: "a\rb"},
+		"hex":             {input: `"\x41\xe9\xFF"`, expected: "Aéÿ"},
+		"four digits":     {input: `"\u00e9\u2028"`, expected: "\u00e9\u2028"},
+		"braces":          {input: `"\u{1f600}\u{41}"`, expected: "\U0001F600A"},
+		"surrogate pair":  {input: `"\ud83d\ude00"`, expected: "\U0001F600"},
+		"upper hex":       {input: `"\uD83D\uDE00"`, expected: "\U0001F600"},
+		"max code point":  {input: `"\u{10FFFF}"`, expected: "\U0010FFFF"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Decode(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %q got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestDecodeEscapeErrors(t *testing.T) {
+	tests := map[string]struct {
+		input  string
+		offset int64
+		escape string
+		reason string
+	}{
+		"unknown":               {input: `"a\qb"`, offset: 2, escape: `\q`, reason: "unknown escape"},
+		"short hex":             {input: `"\x4"`, offset: 1, escape: `\x`, reason: `\x must be followed by 2 hex digits`},
+		"short u":               {input: `"\u12"`, offset: 1, escape: `\u`, reason: `\u must be followed by 4 hex digits`},
+		"empty braces":          {input: `"\u{}"`, offset: 1, escape: `\u{}`, reason: `\u{ must contain at least one hex digit`},
+		"too many digits":       {input: `"\u{1000000}"`, offset: 1, escape: `\u{1000000`, reason: `\u{ can have at most 6 hex digits`},
+		"out of range":          {input: `"\u{110000}"`, offset: 1, escape: `\u{110000}`, reason: "code point out of range"},
+		"not hex":               {input: `"\u{12g}"`, offset: 1, escape: `\u{12g`, reason: `\u{ must contain only hex digits`},
+		"surrogate in braces":   {input: `"\u{d800}"`, offset: 1, escape: `\u{d800}`, reason: `surrogate code points are not allowed in \u{`},
+		"lone high surrogate":   {input: `"a\ud83d"`, offset: 2, escape: `\ud83d`, reason: "high surrogate without a low surrogate"},
+		"lone low surrogate":    {input: `"\ude00"`, offset: 1, escape: `\ude00`, reason: "unexpected low surrogate"},
+		"out of order":          {input: `"\ude00\ud83d"`, offset: 1, escape: `\ude00`, reason: "unexpected low surrogate"},
+		"high then not low":     {input: `"\ud83dA"`, offset: 1, escape: `\ud83d`, reason: "high surrogate without a low surrogate"},
+		"high then high":        {input: `"\ud83d\ud83d"`, offset: 1, escape: `\ud83d\ud83d`, reason: "high surrogate without a low surrogate"},
+		"escape at end of text": {input: `"ab\`, offset: 3, escape: `\`, reason: "unexpected end of input"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Decode(tc.input)
+			var eerr Error
+			if !errors.As(err, &eerr) {
+				t.Fatalf("expected an Error got %v", err)
+			}
+			if eerr.Offset != tc.offset || eerr.Escape != tc.escape || eerr.Reason != tc.reason {
+				t.Errorf("expected %q %q at %v got %q %q at %v", tc.escape, tc.reason, tc.offset, eerr.Escape, eerr.Reason, eerr.Offset)
+			}
+		})
+	}
+}
+
+func TestParserErrors(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		err   error
+		msg   string
+	}{
+		"unterminated":         {input: `x = "abc`, err: ErrUnterminated, msg: "unterminated string starting at 4"},
+		"unterminated escaped": {input: `x = "abc\"`, err: ErrUnterminated, msg: "unterminated string starting at 4"},
+		"newline":              {input: "x = \"ab\ncd\"", err: ErrNewline, msg: "newline in string at 7"},
+		"crlf":                 {input: "x = \"ab\r\ncd\"", err: ErrNewline, msg: "newline in string at 7"},
+		"line separator":       {input: "x = \"ab\u2028cd\"", err: ErrNewline, msg: "newline in string at 7"},
+		"not a string":         {input: `x = abc`, msg: `expected '"' at 4`},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.String(parse.SequenceOf(match.String("x = "), Parser), tc.input)
+			if !state.IsError {
+				t.Fatalf("expected error, got %q", state.Result)
+			}
+			if tc.err != nil && !errors.Is(state.Err, tc.err) {
+				t.Errorf("expected %v got %v", tc.err, state.Err)
+			}
+			if state.Err.Error() != tc.msg {
+				t.Errorf("expected %q got %q", tc.msg, state.Err.Error())
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected string
+	}{
+		"plain":          {input: "café", expected: `"café"`},
+		"escapes":        {input: "\"\\\n\t\r", expected: `"\"\\\n\t\r"`},
+		"control":        {input: "\x00\x1f\x7f\u0085", expected: `"\x00\x1f\x7f\x85"`},
+		"non printable":  {input: "\u2028\U000E0001", expected: `"\u2028\u{e0001}"`},
+		"invalid utf8":   {input: "a\xffb", expected: `"a\ufffdb"`},
+		"replacement":    {input: "\ufffd", expected: "\"\ufffd\""},
+		"emoji":          {input: "\U0001F600", expected: "\"\U0001F600\""},
+		"no break space": {input: "a\u00a0b", expected: `"a\xa0b"`},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := Encode(tc.input); got != tc.expected {
+				t.Errorf("expected %s got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	inputs := []string{
+		"",
+		"plain text",
+		"quote \" and \\ backslash",
+		"\t\r\n",
+		"\x00\x01\x1f\x7f\u0080\u009f",
+		"caf\u00e9 \U0001F600 \u00a0 \u2028\u2029\ufeff\U000E0001",
+		"\ufffd",
+		"\U0010FFFF",
+	}
+	for _, input := range inputs {
+		encoded := Encode(input)
+		decoded, err := Decode(encoded)
+		if err != nil {
+			t.Errorf("%q: unexpected error decoding %s: %v", input, encoded, err)
+			continue
+		}
+		if decoded != input {
+			t.Errorf("%q: round trip through %s gave %q", input, encoded, decoded)
+		}
+	}
+
+	// invalid UTF-8 is lost
+	if decoded, err := Decode(Encode("\xff")); err != nil || decoded != "\uFFFD" {
+		t.Errorf("expected \\xff to decode as \\uFFFD, got %q (%v)", decoded, err)
+	}
+}
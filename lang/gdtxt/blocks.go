@@ -0,0 +1,135 @@
+package gdtxt
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gdey/ppc/parse"
+)
+
+// BlockHandler turns a block into a typed node, by parsing its headers and body.
+// handlers are the handlers in use, for blocks whose body is gdtxt.
+type BlockHandler func(block Block, handlers BlockHandlers) (interface{}, error)
+
+// BlockHandlers maps a Block.Type to the handler for that type of block
+type BlockHandlers map[string]BlockHandler
+
+// Handle runs the handler for the type of block
+// Blocks without a handler are returned as is.
+func (handlers BlockHandlers) Handle(block Block) (interface{}, error) {
+	handler, ok := handlers[block.Type]
+	if !ok {
+		return block, nil
+	}
+	node, err := handler(block, handlers)
+	if err != nil {
+		return nil, BlockError{Block: block, Err: err}
+	}
+	return node, nil
+}
+
+// DefaultBlockHandlers are the handlers used by ParseDocument
+var DefaultBlockHandlers = BlockHandlers{
+	"code": CodeBlockHandler,
+	"list": ListBlockHandler,
+	"note": NoteBlockHandler,
+}
+
+// RegisterBlockHandler sets the handler in DefaultBlockHandlers for the type of block.
+// It is meant to be called from an init function, and is not safe to call while parsing.
+func RegisterBlockHandler(typ string, handler BlockHandler) {
+	if handler == nil {
+		delete(DefaultBlockHandlers, typ)
+		return
+	}
+	DefaultBlockHandlers[typ] = handler
+}
+
+// BlockError is the error returned by a BlockHandler for a block
+type BlockError struct {
+	Block Block
+	Err   error
+}
+
+func (err BlockError) Error() string {
+	return fmt.Sprintf("%v block: %v", err.Block.Type, err.Err)
+}
+
+func (err BlockError) Unwrap() error { return err.Err }
+
+// Header returns the value of the first header with the key, ignoring case
+func (block Block) Header(key string) (string, bool) {
+	for _, header := range block.Headers {
+		if strings.EqualFold(header.Key, key) {
+			return header.Val, true
+		}
+	}
+	return "", false
+}
+
+// trimFirstNewline removes the newline that follows the ';' of a block
+func trimFirstNewline(body string) string {
+	for _, nl := range []string{"\r\n", "\n", "\r"} {
+		if strings.HasPrefix(body, nl) {
+			return body[len(nl):]
+		}
+	}
+	return body
+}
+
+// Code is a «code» block
+type Code struct {
+	// Lang is the lang header
+	Lang    string
+	Text    string
+	Headers []KeyVal
+	Index   int64
+}
+
+// CodeBlockHandler handles «code» blocks
+// result is Code
+func CodeBlockHandler(block Block, _ BlockHandlers) (interface{}, error) {
+	lang, _ := block.Header("lang")
+	return Code{
+		Lang:    lang,
+		Text:    trimFirstNewline(block.UnescapeBody()),
+		Headers: block.Headers,
+		Index:   block.Index,
+	}, nil
+}
+
+// Note is a «note» block, the body of which is gdtxt
+type Note struct {
+	// Elements are like the Elements of a Document, but their indexes are from the start of the body
+	Elements []interface{}
+	Headers  []KeyVal
+	Index    int64
+}
+
+// NoteBlockHandler handles «note» blocks
+// result is Note
+func NoteBlockHandler(block Block, handlers BlockHandlers) (interface{}, error) {
+	elements, err := parseBody(block.UnescapeBody(), handlers)
+	if err != nil {
+		return nil, err
+	}
+	return Note{
+		Elements: elements,
+		Headers:  block.Headers,
+		Index:    block.Index,
+	}, nil
+}
+
+// parseBody parses the body of a block as gdtxt
+func parseBody(body string, handlers BlockHandlers) ([]interface{}, error) {
+	state := parse.String(
+		parse.SequenceOf(IgnoreWhiteSpace, parseDocumentElements, parse.EndOfInput()),
+		strings.TrimRightFunc(body, unicode.IsSpace),
+	)
+	if state.IsError {
+		return nil, state.Err
+	}
+	elements, _ := state.Result.([]interface{})[1].([]interface{})
+	return nestSections(elements, handlers, state.Index)
+}
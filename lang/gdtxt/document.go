@@ -0,0 +1,221 @@
+package gdtxt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gdey/ppc/ast"
+	"github.com/gdey/ppc/parse"
+	"github.com/gdey/ppc/parse/grapheme"
+	"github.com/gdey/ppc/parse/match"
+)
+
+// Document is a parsed gdtxt document.
+// Document, Section, Paragraph, ListTree and ListNode are ast.Nodes; the Children of a
+// Document or Section are the Elements that are ast.Nodes.
+type Document struct {
+	// FrontMatter is from the «front-matter» block at the start of the document, if there is one
+	FrontMatter *FrontMatter
+	// Elements are the Paragraph, ListTree, HorizontalLine and blocks before the first section,
+	// and the Sections that are not within another section. Blocks are the result of their
+	// BlockHandler, or the Block itself if there is no handler for the type.
+	Elements []interface{}
+	// End is the index after the document
+	End int64
+}
+
+func (doc Document) Span() ast.Span       { return ast.Span{Start: 0, End: doc.End} }
+func (doc Document) Children() []ast.Node { return nodes(doc.Elements) }
+
+// Section is a SectionLine and everything up to the next section of the same or a lower level
+type Section struct {
+	SectionLine
+	// Elements are the Paragraph, ListTree, HorizontalLine and blocks of the section,
+	// followed by any sub-sections.
+	Elements []interface{}
+	// End is the index of the next section of the same or a lower level, or the end of the document
+	End int64
+}
+
+func (section Section) Span() ast.Span       { return ast.Span{Start: section.Index, End: section.End} }
+func (section Section) Children() []ast.Node { return nodes(section.Elements) }
+
+// Paragraph is one or more lines of words
+type Paragraph struct {
+	// Lines are the words (WordWhitespace, WordEscaped, WordInlineStyle, WordCharacters) of each line
+	Lines [][]interface{}
+	Index int64
+	End   int64
+}
+
+func (p Paragraph) Span() ast.Span       { return ast.Span{Start: p.Index, End: p.End} }
+func (p Paragraph) Children() []ast.Node { return nil }
+
+// nodes returns the elements that are ast.Nodes
+func nodes(elements []interface{}) []ast.Node {
+	var children []ast.Node
+	for _, element := range elements {
+		if node, ok := element.(ast.Node); ok {
+			children = append(children, node)
+		}
+	}
+	return children
+}
+
+// FrontMatterType is the type of the front-matter block
+const FrontMatterType = "front-matter"
+
+var parseDocumentParagraph = parse.Map(
+	parse.Span(ParseParagraph),
+	func(r interface{}) interface{} {
+		span := r.(parse.Spanned)
+		// ParseParagraph results in [[line...]], where each line is [[word...]]
+		lines := span.Result.([]interface{})[0].([]interface{})
+		paragraph := Paragraph{
+			Lines: make([][]interface{}, len(lines)),
+			Index: span.Start,
+			End:   span.End,
+		}
+		for i := range lines {
+			paragraph.Lines[i] = lines[i].([]interface{})[0].([]interface{})
+		}
+		return paragraph
+	},
+)
+
+var parseDocumentElements = parse.Many(
+	parse.ChoiceOf(
+		ParseBlock,
+		ParseSectionLine,
+		ParseHLine,
+		ParseList,
+		parseDocumentParagraph,
+		parse.Discard(match.Newline()),
+	),
+)
+
+// ParseDocument matches a whole gdtxt document, using DefaultBlockHandlers for the blocks
+// result is *Document
+var ParseDocument = ParseDocumentWith(DefaultBlockHandlers)
+
+// ParseDocumentWith matches a whole gdtxt document, using the handlers for the blocks
+// result is *Document
+func ParseDocumentWith(handlers BlockHandlers) parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		next := parseDocumentElements.Run(state)
+		if next.IsError {
+			return next
+		}
+		if end := parse.EndOfInput().Run(next); end.IsError {
+			return next.WithError(positionError(next, errors.New("unexpected text")))
+		}
+		elements, _ := next.Result.([]interface{})
+		doc, err := buildDocument(elements, handlers, next.Index)
+		if err != nil {
+			// point the error at the offending header or block; errors from
+			// within the block (e.g. an inserted document) have their own positions
+			at := state
+			switch err := err.(type) {
+			case HeaderError:
+				at = state.WithResult(nil, err.Header.Index)
+			case BlockError:
+				at = state.WithResult(nil, err.Block.Index)
+				if herr, ok := err.Err.(HeaderError); ok {
+					at = state.WithResult(nil, herr.Header.Index)
+				}
+			}
+			return at.WithError(positionError(at, err))
+		}
+		return next.WithResult(doc, next.Index)
+	})
+}
+
+// PositionError is an error in a document, at the given line and column
+type PositionError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (err PositionError) Error() string {
+	return fmt.Sprintf("%v:%v: %v", err.Line, err.Column, err.Err)
+}
+
+func (err PositionError) Unwrap() error { return err.Err }
+
+func positionError(state parse.State, err error) PositionError {
+	line, column, _ := state.LineColumn(grapheme.DefaultTabWidth)
+	return PositionError{Line: line, Column: column, Err: err}
+}
+
+// buildDocument takes the front-matter from the elements and nests the rest into sections;
+// end is the index after the elements
+func buildDocument(elements []interface{}, handlers BlockHandlers, end int64) (*Document, error) {
+	doc := &Document{End: end}
+	for i, element := range elements {
+		if element == nil {
+			// blank line
+			continue
+		}
+		if block, ok := element.(Block); ok && block.Type == FrontMatterType {
+			fm, err := NewFrontMatter(block)
+			if err != nil {
+				return nil, err
+			}
+			doc.FrontMatter = fm
+			elements = elements[i+1:]
+		}
+		break
+	}
+	elements, err := nestSections(elements, handlers, end)
+	if err != nil {
+		return nil, err
+	}
+	doc.Elements = elements
+	return doc, nil
+}
+
+// nestSections groups the lists, nests the elements into sections, and runs the handlers on the blocks;
+// end is the index after the elements
+func nestSections(elements []interface{}, handlers BlockHandlers, end int64) ([]interface{}, error) {
+	elements = GroupLists(elements)
+	var (
+		nested []interface{}
+		// sections are the open sections, the innermost last
+		sections []*Section
+	)
+	add := func(element interface{}) {
+		if len(sections) == 0 {
+			nested = append(nested, element)
+			return
+		}
+		parent := sections[len(sections)-1]
+		parent.Elements = append(parent.Elements, element)
+	}
+	for _, element := range elements {
+		switch element := element.(type) {
+		case nil:
+			// blank line
+		case SectionLine:
+			for len(sections) > 0 && sections[len(sections)-1].Level >= element.Level {
+				sections[len(sections)-1].End = element.Index
+				sections = sections[:len(sections)-1]
+			}
+			section := &Section{SectionLine: element}
+			add(section)
+			sections = append(sections, section)
+		case Block:
+			node, err := handlers.Handle(element)
+			if err != nil {
+				return nil, err
+			}
+			add(node)
+		default:
+			add(element)
+		}
+	}
+	for _, section := range sections {
+		section.End = end
+	}
+	return nested, nil
+}
@@ -0,0 +1,102 @@
+package gdtxt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gdey/ppc/ast"
+	"github.com/gdey/ppc/parse"
+)
+
+func parseDocument(t *testing.T, text string) *Document {
+	t.Helper()
+	state := parse.String(ParseDocument, text)
+	if state.IsError {
+		t.Fatalf("unexpected error: %v", state.Err)
+	}
+	return state.Result.(*Document)
+}
+
+func TestDocumentLineEndings(t *testing.T) {
+	tests := map[string]string{
+		"lf":    "§ Title\n\none\ntwo\nthree\n\n§§ Sub\n\n• a\n\n• b\n\n",
+		"crlf":  "§ Title\r\n\r\none\r\ntwo\r\nthree\r\n\r\n§§ Sub\r\n\r\n• a\r\n\r\n• b\r\n\r\n",
+		"cr":    "§ Title\r\rone\rtwo\rthree\r\r§§ Sub\r\r• a\r\r• b\r\r",
+		"mixed": "§ Title\r\n\rone\u2028two\r\nthree\n\u2029§§ Sub\r\r\n• a\u0085\n• b\r\n",
+	}
+	for name, text := range tests {
+		t.Run(name, func(t *testing.T) {
+			doc := parseDocument(t, text)
+			if len(doc.Elements) != 1 {
+				t.Fatalf("expected 1 child got %#v", doc.Elements)
+			}
+			title, ok := doc.Elements[0].(*Section)
+			if !ok || title.Title != "Title" || len(title.Elements) != 2 {
+				t.Fatalf("expected section Title with 2 children got %#v", doc.Elements[0])
+			}
+			paragraph, ok := title.Elements[0].(Paragraph)
+			if !ok || len(paragraph.Lines) != 3 {
+				t.Fatalf("expected a paragraph of 3 lines got %#v", title.Elements[0])
+			}
+			sub, ok := title.Elements[1].(*Section)
+			if !ok || sub.Title != "Sub" || len(sub.Elements) != 1 {
+				t.Fatalf("expected section Sub with 1 child got %#v", title.Elements[1])
+			}
+			list, ok := sub.Elements[0].(ListTree)
+			if !ok || len(list.Items) != 2 || list.Items[0].Text != "a" || list.Items[1].Text != "b" {
+				t.Fatalf("expected a list of a and b got %#v", sub.Elements[0])
+			}
+		})
+	}
+}
+
+func TestPositionErrorLineEndings(t *testing.T) {
+	// the header with the error is on the fifth line, the second line ends with "\r\n",
+	// the third with "\r", and the fourth with U+2028
+	text := "one\n\r\n\r\u2028«list | type : bogus ; • a»\n"
+	state := parse.String(ParseDocument, text)
+	perr, ok := state.Err.(PositionError)
+	if !ok {
+		t.Fatalf("expected a PositionError got %#v", state.Err)
+	}
+	if perr.Line != 5 || perr.Column != 7 {
+		t.Errorf("expected 5:7 got %v:%v", perr.Line, perr.Column)
+	}
+	// the offset of the header is in the position, not the message
+	expected := `5:7: list block: invalid type header "bogus": expected ordered, unordered or checkbox`
+	if perr.Error() != expected {
+		t.Errorf("expected %q got %q", expected, perr.Error())
+	}
+}
+
+func TestDocumentNodes(t *testing.T) {
+	text := "intro\n\n§ One\n\ntext\n\n• a\n\n•• b\n\n§§ Two\n\nmore\n\n§ Three\n\n"
+	doc := parseDocument(t, text)
+
+	var spans []string
+	ast.Inspect(doc, func(node ast.Node) bool {
+		if node == nil {
+			return false
+		}
+		span := node.Span()
+		spans = append(spans, fmt.Sprintf("%T %q", node, text[span.Start:span.End]))
+		return true
+	})
+	expected := []string{
+		fmt.Sprintf("*gdtxt.Document %q", text),
+		`gdtxt.Paragraph "intro\n"`,
+		`*gdtxt.Section "§ One\n\ntext\n\n• a\n\n•• b\n\n§§ Two\n\nmore\n\n"`,
+		`gdtxt.Paragraph "text\n"`,
+		`gdtxt.ListTree "• a\n\n•• b"`,
+		`gdtxt.ListNode "• a\n\n•• b"`,
+		`gdtxt.ListNode "•• b"`,
+		`*gdtxt.Section "§§ Two\n\nmore\n\n"`,
+		`gdtxt.Paragraph "more\n"`,
+		`*gdtxt.Section "§ Three\n\n"`,
+	}
+	if !reflect.DeepEqual(spans, expected) {
+		t.Errorf("expected\n%v\ngot\n%v", strings.Join(expected, "\n"), strings.Join(spans, "\n"))
+	}
+}
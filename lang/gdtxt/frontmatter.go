@@ -0,0 +1,154 @@
+package gdtxt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Author of a document, from a header like "Gautam Dey <gautam.dey77@gmail.com>"
+type Author struct {
+	Name  string
+	Email string
+}
+
+func (a Author) String() string {
+	if a.Email == "" {
+		return a.Name
+	}
+	if a.Name == "" {
+		return "<" + a.Email + ">"
+	}
+	return a.Name + " <" + a.Email + ">"
+}
+
+// FrontMatter is the typed form of the «front-matter» block
+//
+//	«front-matter
+//	| Title : Notes from build text matchers
+//	| Author : Gautam Dey <gautam.dey77@gmail.com>
+//	| Date : 17 Oct 2019
+//	| Lang : en
+//	| Tags : notes, gdtxt
+//	;
+//	A description of the document
+//	»
+//
+// Header keys are not case sensitive.
+type FrontMatter struct {
+	Title  string
+	Author Author
+	Date   time.Time
+	Lang   string
+	Tags   []string
+	// Description is the body of the block
+	Description string
+	// Extras are the headers that are not one of the above
+	Extras []KeyVal
+	Index  int64
+}
+
+// DateFormats are the accepted formats for the Date header, tried in order
+var DateFormats = []string{
+	"2006-01-02",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"Jan 2 2006",
+	"January 2 2006",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05Z07:00",
+}
+
+// HeaderError is the error for a header that does not have a valid value
+type HeaderError struct {
+	Header KeyVal
+	Err    error
+}
+
+func (err HeaderError) Error() string {
+	return fmt.Sprintf("invalid %v header %q: %v", err.Header.Key, err.Header.Val, err.Err)
+}
+
+func (err HeaderError) Unwrap() error { return err.Err }
+
+// ParseAuthor parses "name <email>", "name" or "<email>"
+func ParseAuthor(s string) (Author, error) {
+	s = strings.TrimSpace(s)
+	start := strings.IndexByte(s, '<')
+	if start == -1 {
+		if strings.ContainsAny(s, ">") {
+			return Author{}, errors.New("unexpected '>'")
+		}
+		return Author{Name: s}, nil
+	}
+	if !strings.HasSuffix(s, ">") {
+		return Author{}, errors.New("expected email to end with '>'")
+	}
+	author := Author{
+		Name:  strings.TrimSpace(s[:start]),
+		Email: strings.TrimSpace(s[start+1 : len(s)-1]),
+	}
+	at := strings.IndexByte(author.Email, '@')
+	if at <= 0 || at == len(author.Email)-1 || strings.ContainsAny(author.Email, "<> ") {
+		return Author{}, fmt.Errorf("invalid email %q", author.Email)
+	}
+	return author, nil
+}
+
+// ParseDate parses the date using the first of DateFormats that matches
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, format := range DateFormats {
+		if t, err := time.Parse(format, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("unknown date format")
+}
+
+// NewFrontMatter creates the FrontMatter from a «front-matter» block
+// A header that can not be parsed or is given more than once results in a HeaderError.
+func NewFrontMatter(block Block) (*FrontMatter, error) {
+	if block.Type != FrontMatterType {
+		return nil, fmt.Errorf("expected %v block not %v", FrontMatterType, block.Type)
+	}
+	fm := &FrontMatter{
+		Description: strings.TrimSpace(block.UnescapeBody()),
+		Index:       block.Index,
+	}
+	seen := make(map[string]bool, len(block.Headers))
+	for _, header := range block.Headers {
+		key := strings.ToLower(header.Key)
+		var err error
+		switch key {
+		case "title":
+			fm.Title = header.Val
+		case "author":
+			fm.Author, err = ParseAuthor(header.Val)
+		case "date":
+			fm.Date, err = ParseDate(header.Val)
+		case "lang":
+			fm.Lang = header.Val
+		case "tags":
+			for _, tag := range strings.Split(header.Val, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					fm.Tags = append(fm.Tags, tag)
+				}
+			}
+		default:
+			fm.Extras = append(fm.Extras, header)
+			continue
+		}
+		if err == nil && seen[key] {
+			err = errors.New("header given more than once")
+		}
+		if err != nil {
+			return nil, HeaderError{Header: header, Err: err}
+		}
+		seen[key] = true
+	}
+	return fm, nil
+}
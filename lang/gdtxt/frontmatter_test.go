@@ -0,0 +1,121 @@
+package gdtxt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFrontMatterDescription(t *testing.T) {
+	doc := parseDocument(t, "«front-matter\n| title : Notes\n;\nQuotes are \\«like this\\»\n»\n\ntext\n")
+	if doc.FrontMatter == nil {
+		t.Fatalf("expected front matter")
+	}
+	if doc.FrontMatter.Title != "Notes" {
+		t.Errorf("title, expected %q got %q", "Notes", doc.FrontMatter.Title)
+	}
+	if expected := "Quotes are «like this»"; doc.FrontMatter.Description != expected {
+		t.Errorf("description, expected %q got %q", expected, doc.FrontMatter.Description)
+	}
+}
+
+func TestParseAuthor(t *testing.T) {
+	tests := map[string]struct {
+		input  string
+		author Author
+		err    string
+	}{
+		"name and email": {input: " Gautam Dey <gautam.dey77@gmail.com> ", author: Author{Name: "Gautam Dey", Email: "gautam.dey77@gmail.com"}},
+		"name":           {input: "Gautam Dey", author: Author{Name: "Gautam Dey"}},
+		"email":          {input: "<gd@example.com>", author: Author{Email: "gd@example.com"}},
+		"missing >":      {input: "Gautam Dey <gd@example.com", err: "expected email to end with '>'"},
+		"only >":         {input: "Gautam Dey gd@example.com>", err: "unexpected '>'"},
+		"no @":           {input: "Gautam Dey <gd>", err: `invalid email "gd"`},
+		"empty email":    {input: "Gautam Dey <>", err: `invalid email ""`},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			author, err := ParseAuthor(tc.input)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error %q got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if author != tc.author {
+				t.Errorf("expected %#v got %#v", tc.author, author)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	date := time.Date(2019, time.October, 17, 0, 0, 0, 0, time.UTC)
+	dateTime := time.Date(2019, time.October, 17, 9, 30, 0, 0, time.UTC)
+	for _, format := range DateFormats {
+		expected := date
+		if format == "2006-01-02 15:04" || format == "2006-01-02T15:04:05Z07:00" {
+			expected = dateTime
+		}
+		input := " " + expected.Format(format) + " "
+		got, err := ParseDate(input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", input, err)
+			continue
+		}
+		if !got.Equal(expected) {
+			t.Errorf("%q: expected %v got %v", input, expected, got)
+		}
+	}
+	if _, err := ParseDate("17/10/2019"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestNewFrontMatterHeaders(t *testing.T) {
+	fm, err := NewFrontMatter(Block{
+		Type: FrontMatterType,
+		Headers: []KeyVal{
+			{Key: "Title", Val: "Notes"},
+			{Key: "Tags", Val: "notes, , gdtxt"},
+			{Key: "license", Val: "MIT"},
+			{Key: "license", Val: "BSD"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fm.Title != "Notes" || !reflect.DeepEqual(fm.Tags, []string{"notes", "gdtxt"}) {
+		t.Errorf("expected title Notes and tags notes, gdtxt; got %q, %q", fm.Title, fm.Tags)
+	}
+	// unknown headers are kept as extras, even when given more than once
+	expected := []KeyVal{{Key: "license", Val: "MIT"}, {Key: "license", Val: "BSD"}}
+	if !reflect.DeepEqual(fm.Extras, expected) {
+		t.Errorf("extras, expected %#v got %#v", expected, fm.Extras)
+	}
+
+	duplicate := KeyVal{Key: "TITLE", Val: "Again", Index: 20}
+	_, err = NewFrontMatter(Block{
+		Type:    FrontMatterType,
+		Headers: []KeyVal{{Key: "title", Val: "Notes"}, duplicate},
+	})
+	var herr HeaderError
+	if !errors.As(err, &herr) || herr.Header != duplicate {
+		t.Fatalf("expected a HeaderError for %#v, got %v", duplicate, err)
+	}
+	if expected := `invalid TITLE header "Again": header given more than once`; err.Error() != expected {
+		t.Errorf("expected %q got %q", expected, err.Error())
+	}
+
+	_, err = NewFrontMatter(Block{
+		Type:    FrontMatterType,
+		Headers: []KeyVal{{Key: "date", Val: "someday"}},
+	})
+	if !errors.As(err, &herr) || herr.Header.Key != "date" {
+		t.Errorf("expected a HeaderError for the date, got %v", err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"unicode"
 
 	"github.com/gdey/ppc/parse"
+	"github.com/gdey/ppc/parse/charclass"
+	"github.com/gdey/ppc/parse/grapheme"
 	"github.com/gdey/ppc/parse/match"
 )
 
@@ -25,7 +27,7 @@ func UntilString(end parse.Parser, body parse.Parser) parse.Parser {
 					panic("All results should be a rune")
 				}
 			}
-			return string(rbyt)
+			return parse.RunesToString(rbyt)
 		},
 	)
 }
@@ -62,7 +64,7 @@ func MaybeAsString(r interface{}) interface{} {
 	if !ok {
 		return r
 	}
-	return string(result)
+	return parse.RunesToString(result)
 }
 
 func MaybeAsStringMap(fn func(string) string) func(interface{}) interface{} {
@@ -71,7 +73,7 @@ func MaybeAsStringMap(fn func(string) string) func(interface{}) interface{} {
 		if !ok {
 			return r
 		}
-		return fn(string(result))
+		return fn(parse.RunesToString(result))
 	}
 }
 
@@ -98,8 +100,8 @@ type SectionLine struct {
 
 var matchStringTillEndOfLine = parse.Map(
 	match.Runes(
-		func(r rune) bool { return r != '\n' },
-		errors.New("any rune other then '\\n'"),
+		func(r rune) bool { return !parse.IsNewline(r) },
+		errors.New("any rune other then a newline"),
 	),
 	MaybeAsStringMap(strings.TrimSpace),
 )
@@ -107,15 +109,15 @@ var matchStringTillEndOfLine = parse.Map(
 var matchLineStart = parse.Discard(
 	parse.ChoiceOf(
 		parse.StartOfInput(),
-		parse.Many(match.String("\n")),
+		parse.Many(match.Newline()),
 	),
 )
 var matchLineEnd = parse.Discard(
 	parse.SequenceOf(
-		match.String("\n"),
+		match.Newline(),
 		parse.ChoiceOf(
 			parse.EndOfInput(),
-			match.String("\n"),
+			match.Newline(),
 		),
 	),
 )
@@ -143,12 +145,12 @@ func MatchLine(body parse.Parser) parse.Parser {
 }
 
 // Paragraphs are made up of lines
-// Lines are made up of words followed by "\n"
+// Lines are made up of words followed by a newline ("\n", "\r\n", "\r", ...)
 // Words are:
-//    Whitespace excluding "\n"
+//    Whitespace excluding newlines
 //    in-line attributes
 //    escaped values \...
-//    anything that is not (whitespace, "[", "\", or "\n") (WordLetters)
+//    anything that is not (whitespace, "[", or "\") (WordLetters)
 
 type WordWhitespace struct {
 	Index int64
@@ -157,7 +159,7 @@ type WordWhitespace struct {
 
 var ParseWordWhitespace = parse.MapIndex(
 	match.Runes(func(r rune) bool {
-		return r != '\n' && unicode.IsSpace(r)
+		return !parse.IsNewline(r) && unicode.IsSpace(r)
 	},
 		errors.New("failed to match non-newline whitespace"),
 	),
@@ -198,9 +200,9 @@ var ParseWordEscaped = parse.MapIndex(
 		parse.Func(func(state parse.State) parse.State {
 			r, n, err := state.ReadNextRune()
 			if err != nil {
-				return state.WithError(fmt.Errorf("error reading escaped val: %v ", err))
+				return state.WithError(fmt.Errorf("error reading escaped val: %w ", err))
 			}
-			return state.WithResult(string([]rune{r}), state.Index+int64(n))
+			return state.WithResult(parse.RunesToString([]rune{r}), state.Index+int64(n))
 		}),
 	),
 	func(r interface{}, idx int64) interface{} {
@@ -216,37 +218,227 @@ type WordCharacters struct {
 	Text  string
 }
 
-var ParseWordCharacters = parse.MapIndex(
-	match.Runes(func(r rune) bool {
-		if unicode.IsSpace(r) {
-			return false
-		}
-		return true
-		//return r != '[' && r != '\\'
-	},
-		errors.New("failed to match word characters"),
+// wordCharacter is anything that is not whitespace, "[", or "\"
+var wordCharacter = match.Except(
+	match.AnyRune(),
+	parse.ChoiceOf(
+		match.Space(),
+		match.String("["),
+		match.String(`\`),
 	),
+)
+
+// wordCharacters matches one or more of the given characters as WordCharacters
+func wordCharacters(character parse.Parser) parse.Parser {
+	return parse.MapIndex(
+		parse.MapError(
+			parse.Many1(character),
+			func(state parse.State) error {
+				if parse.IsInvalidUTF8(state.Err) {
+					return state.Err
+				}
+				return errors.New("failed to match word characters")
+			},
+		),
+		func(r interface{}, idx int64) interface{} {
+			results := r.([]interface{})
+			rs := make([]rune, len(results))
+			for i := range results {
+				rs[i] = results[i].(rune)
+			}
+			return WordCharacters{
+				Text:  parse.RunesToString(rs),
+				Index: idx,
+			}
+		},
+	)
+}
+
+var ParseWordCharacters = wordCharacters(wordCharacter)
+
+// parseWordOpenBracket matches a "[" that does not start anything else
+var parseWordOpenBracket = parse.MapIndex(
+	match.String("["),
 	func(r interface{}, idx int64) interface{} {
 		return WordCharacters{
-			Text:  string(r.([]rune)),
+			Text:  r.(string),
 			Index: idx,
 		}
 	},
 )
-var ParseWord = parse.ChoiceOf(
-	ParseWordWhitespace,
-	ParseWordEscaped,
-	ParseWordCharacters,
-)
 
-var ParsePLine = parse.SequenceOfNoNil(
-	parse.Many1(ParseWord),
-	parse.Discard(parse.ChoiceOf(
-		match.String("\n"),
-		parse.EndOfInput(),
-	)),
+// Inline styles are words between "[" and "]", with a marker for the style after the "["
+// and before the "]":
+//    [/ emphasize /]
+//    [* strong *]
+//    [- strike through -]
+//    [_ underline _]
+//    [! callout !]
+//    [" quote "]
+// Styles can be nested, e.g. "[* strong and [/ emphasized /] *]", but do not go past the
+// end of the line. A "[" that does not start a style is just a character.
+
+var inlineStyleMarkers = [...]string{
+	InlineStyleEmphasize:     "/",
+	InlineStyleStrong:        "*",
+	InlineStyleStrikeThrough: "-",
+	InlineStyleUnderline:     "_",
+	InlineStyleCallout:       "!",
+	InlineStyleQuote:         `"`,
+}
+
+var (
+	inlineStyleOpen  [len(inlineStyleMarkers)]parse.Parser
+	inlineStyleClose [len(inlineStyleMarkers)]parse.Parser
+	// inlineStyleCharacters matches the word characters within each style, up to its close
+	inlineStyleCharacters [len(inlineStyleMarkers)]parse.Parser
 )
 
+func init() {
+	for style, marker := range inlineStyleMarkers {
+		inlineStyleOpen[style] = match.String("[" + marker)
+		inlineStyleClose[style] = match.String(marker + "]")
+		inlineStyleCharacters[style] = wordCharacters(match.Except(wordCharacter, inlineStyleClose[style]))
+	}
+}
+
+// trimWordWhitespace removes the WordWhitespace at the start and end of words
+func trimWordWhitespace(words []interface{}) []interface{} {
+	for len(words) > 0 {
+		if _, ok := words[0].(WordWhitespace); !ok {
+			break
+		}
+		words = words[1:]
+	}
+	for len(words) > 0 {
+		if _, ok := words[len(words)-1].(WordWhitespace); !ok {
+			break
+		}
+		words = words[:len(words)-1]
+	}
+	return words
+}
+
+// inlineStyles matches the words of a line, remembering the result of the inline style at
+// each index. When a style is not closed the styles within it are tried again as plain
+// words; without the results a line of unclosed styles would take exponential time.
+type inlineStyles struct {
+	// contents matches the words of each style, they can be inline styles themselves
+	contents [len(inlineStyleMarkers)]parse.Parser
+	word     parse.Parser
+	line     parse.Parser
+	results  map[int64]parse.State
+	// rest is the text from start to the end of the line
+	start int64
+	rest  *string
+}
+
+func newInlineStyles() *inlineStyles {
+	styles := &inlineStyles{results: make(map[int64]parse.State)}
+	style := parse.Func(styles.run)
+	for i := range inlineStyleMarkers {
+		styles.contents[i] = parse.Many1(parse.ChoiceOf(
+			ParseWordWhitespace,
+			ParseWordEscaped,
+			style,
+			inlineStyleCharacters[i],
+			parseWordOpenBracket,
+		))
+	}
+	styles.word = parse.ChoiceOf(
+		ParseWordWhitespace,
+		ParseWordEscaped,
+		style,
+		ParseWordCharacters,
+		parseWordOpenBracket,
+	)
+	styles.line = parse.SequenceOfNoNil(
+		parse.Many1(styles.word),
+		parse.Discard(parse.ChoiceOf(
+			match.Newline(),
+			parse.EndOfInput(),
+		)),
+	)
+	return styles
+}
+
+func (styles *inlineStyles) run(state parse.State) parse.State {
+	if result, ok := styles.results[state.Index]; ok {
+		return result
+	}
+	result := styles.match(state)
+	styles.results[state.Index] = result
+	return result
+}
+
+// closed reports whether the close of the style is on the line after the index
+func (styles *inlineStyles) closed(state parse.State, style int) bool {
+	if styles.rest == nil || state.Index < styles.start {
+		rest := ""
+		for size := 256; ; size *= 2 {
+			buff, n, _ := state.ReadNextBytes(size)
+			rest = string(buff[:n])
+			if end := strings.IndexFunc(rest, parse.IsNewline); end >= 0 {
+				rest = rest[:end]
+				break
+			}
+			if n < size {
+				break
+			}
+		}
+		styles.start, styles.rest = state.Index, &rest
+	}
+	offset := state.Index - styles.start
+	if offset > int64(len(*styles.rest)) {
+		return false
+	}
+	return strings.Contains((*styles.rest)[offset:], inlineStyleMarkers[style]+"]")
+}
+
+func (styles *inlineStyles) match(state parse.State) parse.State {
+	for style := range inlineStyleMarkers {
+		open := inlineStyleOpen[style].Run(state)
+		if open.IsError {
+			continue
+		}
+		if !styles.closed(open, style) {
+			break
+		}
+		contents := styles.contents[style].Run(open)
+		if contents.IsError {
+			break
+		}
+		end := inlineStyleClose[style].Run(contents)
+		if end.IsError {
+			break
+		}
+		return end.WithResult(
+			WordInlineStyle{
+				Style:    InlineStyle(style),
+				Contents: trimWordWhitespace(contents.Result.([]interface{})),
+				Index:    state.Index,
+			},
+			end.Index,
+		)
+	}
+	return state.WithError(fmt.Errorf("failed to match inline style at %v", state.Index))
+}
+
+// ParseWordInlineStyle matches an inline style
+// result is WordInlineStyle
+var ParseWordInlineStyle = parse.Func(func(state parse.State) parse.State {
+	return newInlineStyles().run(state)
+})
+
+var ParseWord = parse.Func(func(state parse.State) parse.State {
+	return newInlineStyles().word.Run(state)
+})
+
+// ParsePLine matches the words of a line, and the newline after them
+var ParsePLine = parse.Func(func(state parse.State) parse.State {
+	return newInlineStyles().line.Run(state)
+})
+
 var ParseParagraph = parse.SequenceOfNoNil(
 	parse.Many1(ParsePLine),
 )
@@ -325,6 +517,8 @@ type List struct {
 	LevelText []string
 	Text      string
 	Index     int64
+	// End is the index after the text
+	End int64
 }
 
 var ParseListMarker = parse.ChoiceOf(
@@ -365,13 +559,14 @@ var ParseListMarker = parse.ChoiceOf(
 )
 
 var ParseList = MatchLine(
-	parse.MapIndex(
-		parse.SequenceOf(
+	parse.Map(
+		parse.Span(parse.SequenceOf(
 			parse.Many1(ParseListMarker),
 			matchStringTillEndOfLine,
-		),
-		func(r interface{}, idx int64) interface{} {
-			results := r.([]interface{})
+		)),
+		func(r interface{}) interface{} {
+			span := r.(parse.Spanned)
+			results := span.Result.([]interface{})
 			lvls := results[0].([]interface{})
 			level := uint(len(lvls) - 1)
 			lvlText := make([]string, len(lvls))
@@ -380,7 +575,8 @@ var ParseList = MatchLine(
 			}
 			text := results[1].(string)
 			return List{
-				Index:     idx,
+				Index:     span.Start,
+				End:       span.End,
 				Level:     level,
 				LevelText: lvlText,
 				Text:      text,
@@ -394,7 +590,7 @@ var ParseLineTypes = parse.ChoiceOf(
 	ParseHLine,
 	ParseList,
 	ParseParagraph,
-	match.String("\n"),
+	match.Newline(),
 )
 
 // block parser
@@ -408,8 +604,27 @@ type KeyVal struct {
 type Block struct {
 	Type    string
 	Headers []KeyVal
-	Body    string
-	Index   int64
+	// Body is the text between the ';' and the '»', as is; a '»' in the body is escaped as `\»`
+	Body  string
+	Index int64
+}
+
+// parseBlockBody matches anything up to the closing '»', skipping over escaped runes
+var parseBlockBody = parse.Text(
+	parse.Many(
+		parse.ChoiceOf(
+			parse.SequenceOf(match.String(`\`), match.AnyRune()),
+			match.Except(match.AnyRune(), match.String("»")),
+		),
+	),
+)
+
+var blockBodyUnescaper = strings.NewReplacer(`\«`, "«", `\»`, "»")
+
+// UnescapeBody returns the body of the block with `\«` and `\»` replaced by '«' and '»'.
+// Other escapes are left as is, as the body may be code.
+func (block Block) UnescapeBody() string {
+	return blockBodyUnescaper.Replace(block.Body)
 }
 
 var ParseBlockHeader = parse.MapIndex(
@@ -423,7 +638,7 @@ var ParseBlockHeader = parse.MapIndex(
 		parse.Map(
 			parse.SequenceOf(
 				IgnoreWhiteSpace,
-				match.Letters(),
+				parseBlockHeaderKey,
 				IgnoreWhiteSpace,
 				parse.ChoiceOf(
 					match.String(":"),
@@ -480,12 +695,20 @@ var ParseBlockHeaders = parse.Map(
 	},
 )
 
+// blockTypeClass are the runes of block types and header keys
+var blockTypeClass = charclass.MustParse(`[\pL\p{Nd}._-]`)
+
+var parseBlockHeaderKey = parse.Map(
+	match.Runes(
+		blockTypeClass.Contains,
+		errors.New("failed to match header key"),
+	),
+	MaybeAsString,
+)
+
 var ParseBlockType = parse.Map(
-	match.Runes(func(r rune) bool {
-		return unicode.IsLetter(r) ||
-			unicode.IsDigit(r) ||
-			r == '.' || r == '-' || r == '_'
-	},
+	match.Runes(
+		blockTypeClass.Contains,
 		errors.New("failed to match identifier"),
 	),
 	MaybeAsString,
@@ -512,14 +735,18 @@ var ParseBlock = parse.MapIndex(
 					ctxString = string(contextBytes[:n])
 
 				}
-				return fmt.Errorf("Expected to find ';' at %v -- %v", state.Index, ctxString)
+				line, column, _ := state.LineColumn(grapheme.DefaultTabWidth)
+				return fmt.Errorf("Expected to find ';' at %v:%v -- %v", line, column, ctxString)
 			},
 		),
-		parse.Map(
-			match.Runes(func(r rune) bool { return r != '»' }, errors.New("any rune other then '»'")),
-			MaybeAsString,
+		parseBlockBody,
+		parse.SequenceOf(
+			match.String("»"),
+			parse.ChoiceOf(
+				match.Newline(),
+				parse.EndOfInput(),
+			),
 		),
-		match.String("»\n"),
 	),
 	func(r interface{}, idx int64) interface{} {
 		results, ok := r.([]interface{})
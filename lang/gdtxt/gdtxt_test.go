@@ -0,0 +1,92 @@
+package gdtxt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdey/ppc/parse"
+)
+
+func TestParseWordInlineStyle(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		words []interface{}
+	}{
+		"strong": {
+			input: "[* a *]",
+			words: []interface{}{
+				WordInlineStyle{Style: InlineStyleStrong, Index: 0, Contents: []interface{}{
+					WordCharacters{Index: 3, Text: "a"},
+				}},
+			},
+		},
+		"nested": {
+			input: "[* a [/ b /] *]",
+			words: []interface{}{
+				WordInlineStyle{Style: InlineStyleStrong, Index: 0, Contents: []interface{}{
+					WordCharacters{Index: 3, Text: "a"},
+					WordWhitespace{Index: 4, Text: " "},
+					WordInlineStyle{Style: InlineStyleEmphasize, Index: 5, Contents: []interface{}{
+						WordCharacters{Index: 8, Text: "b"},
+					}},
+				}},
+			},
+		},
+		"unclosed inner": {
+			input: "[* a [/ b *]",
+			words: []interface{}{
+				WordInlineStyle{Style: InlineStyleStrong, Index: 0, Contents: []interface{}{
+					WordCharacters{Index: 3, Text: "a"},
+					WordWhitespace{Index: 4, Text: " "},
+					WordCharacters{Index: 5, Text: "["},
+					WordCharacters{Index: 6, Text: "/"},
+					WordWhitespace{Index: 7, Text: " "},
+					WordCharacters{Index: 8, Text: "b"},
+				}},
+			},
+		},
+		"unclosed": {
+			input: "[* a",
+			words: []interface{}{
+				WordCharacters{Index: 0, Text: "["},
+				WordCharacters{Index: 1, Text: "*"},
+				WordWhitespace{Index: 2, Text: " "},
+				WordCharacters{Index: 3, Text: "a"},
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.String(parse.Many1(ParseWord), tc.input)
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			if !reflect.DeepEqual(state.Result, tc.words) {
+				t.Errorf("expected %#v\ngot %#v", tc.words, state.Result)
+			}
+		})
+	}
+}
+
+func TestParseWordInlineStyleUnclosed(t *testing.T) {
+	// styles that are not closed are retried as plain words, this must not take exponential time
+	tests := map[string]string{
+		"unclosed":        strings.Repeat("[* a ", 200),
+		"mixed unclosed":  strings.Repeat("[* a [/ b [_ c ", 100),
+		"only last close": strings.Repeat("[* ", 200) + "x *]",
+	}
+	for name, line := range tests {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			doc := parseDocument(t, line+"\n")
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("took %v", elapsed)
+			}
+			if len(doc.Elements) != 1 {
+				t.Errorf("expected one paragraph got %#v", doc.Elements)
+			}
+		})
+	}
+}
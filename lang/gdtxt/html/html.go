@@ -0,0 +1,384 @@
+/*
+Package html renders a gdtxt document as HTML.
+
+Sections become headings (h1 to h6) with an id for linking to them, paragraphs become <p>,
+lists become <ul> or <ol> (with disabled checkboxes for checkbox items), horizontal lines
+become <hr>, code blocks become <pre><code class="language-LANG">, and inline styles
+become <em>, <strong>, <s>, <u>, <mark> and <q>. All text is escaped.
+
+	state := parse.String(gdtxt.ParseDocument, text)
+	if state.IsError {
+		return state.Err
+	}
+	return html.Renderer{}.Render(os.Stdout, state.Result.(*gdtxt.Document))
+
+The page around the body is a html/template, which can be changed with Renderer.Template.
+*/
+package html
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gdey/ppc/lang/gdtxt"
+)
+
+// DefaultTemplate is the page template used when Renderer.Template is nil.
+// It is executed with a Page.
+var DefaultTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html{{with .Lang}} lang="{{.}}"{{end}}>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+{{- with .Author}}
+<meta name="author" content="{{.}}">
+{{- end}}
+</head>
+<body>
+{{.Body}}
+</body>
+</html>
+`))
+
+// Page is what the page template is executed with
+type Page struct {
+	// Title, Author, Lang, Date and Tags are from the front matter, if there is one.
+	// Title is the first heading if the front matter has no title.
+	Title  string
+	Author string
+	Lang   string
+	Date   time.Time
+	Tags   []string
+	// FrontMatter is nil if the document has none
+	FrontMatter *gdtxt.FrontMatter
+	// Headings are all the headings of the body, in order
+	Headings []Heading
+	// Body is the rendered document
+	Body template.HTML
+}
+
+// Heading is a rendered section heading
+type Heading struct {
+	Level int
+	Title string
+	// ID is the id attribute of the heading
+	ID string
+}
+
+// Renderer renders documents as HTML
+type Renderer struct {
+	// Template is the page template, executed with a Page. If nil DefaultTemplate is used.
+	Template *template.Template
+	// IDPrefix is put before the id of each heading
+	IDPrefix string
+	// Node, if not nil, is called for each node before it is rendered. If it returns true
+	// the node is rendered as the returned HTML instead.
+	Node func(node interface{}) (template.HTML, bool)
+}
+
+// Render writes the document as a page, using the template
+func (r Renderer) Render(w io.Writer, doc *gdtxt.Document) error {
+	page, err := r.Page(doc)
+	if err != nil {
+		return err
+	}
+	tmpl := r.Template
+	if tmpl == nil {
+		tmpl = DefaultTemplate
+	}
+	return tmpl.Execute(w, page)
+}
+
+// Page renders the document, and returns it as a Page for a template
+func (r Renderer) Page(doc *gdtxt.Document) (Page, error) {
+	rw := writer{Renderer: r, ids: make(map[string]bool)}
+	if err := rw.children(doc.Elements); err != nil {
+		return Page{}, err
+	}
+	page := Page{
+		FrontMatter: doc.FrontMatter,
+		Headings:    rw.headings,
+		Body:        template.HTML(rw.String()),
+	}
+	if fm := doc.FrontMatter; fm != nil {
+		page.Title = fm.Title
+		page.Author = fm.Author.Name
+		page.Lang = fm.Lang
+		page.Date = fm.Date
+		page.Tags = fm.Tags
+	}
+	if page.Title == "" && len(page.Headings) > 0 {
+		page.Title = page.Headings[0].Title
+	}
+	return page, nil
+}
+
+// Body renders just the document, without the page template
+func (r Renderer) Body(doc *gdtxt.Document) (template.HTML, error) {
+	page, err := r.Page(doc)
+	return page.Body, err
+}
+
+// writer renders the nodes of one document
+type writer struct {
+	Renderer
+	strings.Builder
+	headings []Heading
+	// ids are the heading ids used so far
+	ids map[string]bool
+}
+
+func (w *writer) text(s string) {
+	w.WriteString(template.HTMLEscapeString(s))
+}
+
+func (w *writer) children(nodes []interface{}) error {
+	for _, node := range nodes {
+		if err := w.node(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *writer) node(node interface{}) error {
+	if w.Node != nil {
+		if html, ok := w.Node(node); ok {
+			w.WriteString(string(html))
+			return nil
+		}
+	}
+	switch node := node.(type) {
+	case *gdtxt.Section:
+		w.heading(node.SectionLine)
+		return w.children(node.Elements)
+	case gdtxt.SectionLine:
+		w.heading(node)
+	case gdtxt.Paragraph:
+		w.WriteString("<p>")
+		for i, line := range node.Lines {
+			if i > 0 {
+				w.WriteString("\n")
+			}
+			if err := w.words(line); err != nil {
+				return err
+			}
+		}
+		w.WriteString("</p>\n")
+	case gdtxt.HorizontalLine:
+		w.WriteString("<hr>\n")
+	case gdtxt.ListTree:
+		w.listNodes(node.Items)
+	case gdtxt.ListBlock:
+		w.listBlock(node, node.Items, node.Start)
+	case gdtxt.Code:
+		w.WriteString("<pre><code")
+		if node.Lang != "" {
+			w.WriteString(` class="language-`)
+			w.text(className(node.Lang))
+			w.WriteString(`"`)
+		}
+		w.WriteString(">")
+		w.text(node.Text)
+		w.WriteString("</code></pre>\n")
+	case gdtxt.Note:
+		w.WriteString("<aside class=\"note\">\n")
+		if err := w.children(node.Elements); err != nil {
+			return err
+		}
+		w.WriteString("</aside>\n")
+	case gdtxt.Insert:
+		return w.children(node.Document.Elements)
+	case gdtxt.Block:
+		w.WriteString(`<pre class="block-`)
+		w.text(className(node.Type))
+		w.WriteString(`">`)
+		w.text(node.UnescapeBody())
+		w.WriteString("</pre>\n")
+	default:
+		return fmt.Errorf("can not render %T as html", node)
+	}
+	return nil
+}
+
+var inlineStyleTags = map[gdtxt.InlineStyle]string{
+	gdtxt.InlineStyleEmphasize:     "em",
+	gdtxt.InlineStyleStrong:        "strong",
+	gdtxt.InlineStyleStrikeThrough: "s",
+	gdtxt.InlineStyleUnderline:     "u",
+	gdtxt.InlineStyleCallout:       "mark",
+	gdtxt.InlineStyleQuote:         "q",
+}
+
+func (w *writer) words(words []interface{}) error {
+	for _, word := range words {
+		if w.Node != nil {
+			if html, ok := w.Node(word); ok {
+				w.WriteString(string(html))
+				continue
+			}
+		}
+		switch word := word.(type) {
+		case gdtxt.WordWhitespace:
+			w.text(word.Text)
+		case gdtxt.WordCharacters:
+			w.text(word.Text)
+		case gdtxt.WordEscaped:
+			w.text(word.Text)
+		case gdtxt.WordInlineStyle:
+			tag, ok := inlineStyleTags[word.Style]
+			if !ok {
+				return fmt.Errorf("can not render inline style %v as html", word.Style)
+			}
+			w.WriteString("<" + tag + ">")
+			if err := w.words(word.Contents); err != nil {
+				return err
+			}
+			w.WriteString("</" + tag + ">")
+		default:
+			return fmt.Errorf("can not render %T as html", word)
+		}
+	}
+	return nil
+}
+
+func (w *writer) heading(line gdtxt.SectionLine) {
+	level := int(line.Level)
+	if level < 1 {
+		level = 1
+	}
+	if level > 6 {
+		level = 6
+	}
+	id := w.id(line.Title)
+	w.headings = append(w.headings, Heading{Level: level, Title: line.Title, ID: id})
+	fmt.Fprintf(w, `<h%d id="`, level)
+	w.text(id)
+	w.WriteString(`">`)
+	w.text(line.Title)
+	fmt.Fprintf(w, "</h%d>\n", level)
+}
+
+// id returns a unique id for the heading title; "Lists as blocks" is "lists-as-blocks"
+func (w *writer) id(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+			continue
+		}
+		dash = true
+	}
+	id := b.String()
+	if id == "" {
+		id = "section"
+	}
+	id = w.IDPrefix + id
+	unique := id
+	for i := 1; w.ids[unique]; i++ {
+		unique = id + "-" + strconv.Itoa(i)
+	}
+	w.ids[unique] = true
+	return unique
+}
+
+// className keeps the letters, digits and "-_.+#" of s, other runes become '-';
+// so a lang of `c"><b` does not depend on escaping to stay in the attribute
+func className(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_.+#", r) {
+			return r
+		}
+		return '-'
+	}, s)
+}
+
+func (w *writer) checkbox(checked bool) {
+	if checked {
+		w.WriteString(`<input type="checkbox" disabled checked> `)
+		return
+	}
+	w.WriteString(`<input type="checkbox" disabled> `)
+}
+
+func ordered(node gdtxt.ListNode) bool {
+	return node.Marker.Kind == gdtxt.MarkerNumbered || node.Marker.Kind == gdtxt.MarkerHash
+}
+
+// listNodes writes the items as lists; a run of numbered or hash items is an <ol>, other items a <ul>
+func (w *writer) listNodes(items []gdtxt.ListNode) {
+	for len(items) > 0 {
+		n := 1
+		for n < len(items) && ordered(items[n]) == ordered(items[0]) {
+			n++
+		}
+		tag := "ul"
+		if ordered(items[0]) {
+			tag = "ol"
+		}
+		w.WriteString("<" + tag + ">\n")
+		for _, item := range items[:n] {
+			w.WriteString("<li>")
+			if item.Marker.Kind == gdtxt.MarkerCheckbox {
+				w.checkbox(item.Marker.Checked)
+			}
+			w.text(item.Text)
+			if len(item.Items) > 0 {
+				w.WriteString("\n")
+				w.listNodes(item.Items)
+			}
+			w.WriteString("</li>\n")
+		}
+		w.WriteString("</" + tag + ">\n")
+		items = items[n:]
+	}
+}
+
+var listTypeAttributes = map[gdtxt.NumberStyle]string{
+	gdtxt.NumberAlpha:      "a",
+	gdtxt.NumberUpperAlpha: "A",
+	gdtxt.NumberRoman:      "i",
+	gdtxt.NumberUpperRoman: "I",
+}
+
+func (w *writer) listBlock(list gdtxt.ListBlock, items []gdtxt.ListBlockItem, start int) {
+	if list.Type == gdtxt.ListOrdered {
+		w.WriteString("<ol")
+		if typ, ok := listTypeAttributes[list.Style]; ok {
+			fmt.Fprintf(w, ` type="%v"`, typ)
+		}
+		if start != 1 {
+			fmt.Fprintf(w, ` start="%d"`, start)
+		}
+		w.WriteString(">\n")
+	} else {
+		w.WriteString("<ul>\n")
+	}
+	for _, item := range items {
+		w.WriteString("<li>")
+		if item.Checkbox {
+			w.checkbox(item.Checked)
+		}
+		w.text(item.Text)
+		if len(item.Items) > 0 {
+			w.WriteString("\n")
+			w.listBlock(list, item.Items, 1)
+		}
+		w.WriteString("</li>\n")
+	}
+	if list.Type == gdtxt.ListOrdered {
+		w.WriteString("</ol>\n")
+		return
+	}
+	w.WriteString("</ul>\n")
+}
@@ -0,0 +1,92 @@
+package html
+
+import (
+	"flag"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdey/ppc/lang/gdtxt"
+	"github.com/gdey/ppc/parse"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestGolden renders each testdata/NAME.gdtxt and compares the body to testdata/NAME.html
+func TestGolden(t *testing.T) {
+	renderers := map[string]Renderer{
+		"id-prefix": {IDPrefix: "doc-"},
+		"node-hook": {Node: func(node interface{}) (template.HTML, bool) {
+			switch node := node.(type) {
+			case gdtxt.HorizontalLine:
+				return `<hr class="fancy">` + "\n", true
+			case gdtxt.WordCharacters:
+				if node.Text == "gdtxt" {
+					return "<abbr>gdtxt</abbr>", true
+				}
+			}
+			return "", false
+		}},
+	}
+	files, err := filepath.Glob(filepath.Join("testdata", "*.gdtxt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no test files")
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".gdtxt")
+		t.Run(name, func(t *testing.T) {
+			text, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			state := parse.String(gdtxt.ParseDocument, string(text))
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			body, err := renderers[name].Body(state.Result.(*gdtxt.Document))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			golden := filepath.Join("testdata", name+".html")
+			if *update {
+				if err := os.WriteFile(golden, []byte(body), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != string(expected) {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, body)
+			}
+		})
+	}
+}
+
+func TestPage(t *testing.T) {
+	state := parse.String(gdtxt.ParseDocument, "«front-matter\n| Title : <Notes>\n| Author : Gautam Dey <gd@example.com>\n| Lang : en\n;\n»\n\n§ First\n\n§ First\n")
+	if state.IsError {
+		t.Fatalf("unexpected error: %v", state.Err)
+	}
+	var b strings.Builder
+	if err := (Renderer{}).Render(&b, state.Result.(*gdtxt.Document)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		`<html lang="en">`,
+		`<title>&lt;Notes&gt;</title>`,
+		`<meta name="author" content="Gautam Dey">`,
+		`<h1 id="first">First</h1>`,
+		`<h1 id="first-1">First</h1>`,
+	} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, b.String())
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package gdtxt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdey/ppc/parse/input"
+)
+
+// An «insert» block places the document it references at that point:
+//
+//	«insert | file: chapter-1.gdtxt ; »
+//
+// The reference is resolved by a Resolver, relative to the document the block is in.
+// Inserts are only handled by an Inserter, ParseDocument keeps them as a Block.
+
+// InsertType is the type of the insert block
+const InsertType = "insert"
+
+// DefaultInsertDepth is the depth limit used when Inserter.MaxDepth is zero
+const DefaultInsertDepth = 16
+
+// Resolver finds the documents referenced by «insert» blocks
+type Resolver interface {
+	// Resolve returns the name and content of the document ref, referenced from the document
+	// named from. from is empty for the first document. Documents with the same name are the
+	// same document.
+	Resolve(from string, ref string) (name string, content []byte, err error)
+}
+
+// FileResolver resolves references as file paths, relative to the directory of the
+// referencing file.
+type FileResolver struct{}
+
+func (FileResolver) Resolve(from string, ref string) (string, []byte, error) {
+	name := filepath.FromSlash(ref)
+	if from != "" && !filepath.IsAbs(name) {
+		name = filepath.Join(filepath.Dir(from), name)
+	}
+	name = filepath.Clean(name)
+	content, err := os.ReadFile(name)
+	return name, content, err
+}
+
+// MapResolver resolves references to the documents in the map, keyed by their slash
+// separated path. Useful for tests.
+type MapResolver map[string]string
+
+func (m MapResolver) Resolve(from string, ref string) (string, []byte, error) {
+	name := ref
+	if from != "" && !path.IsAbs(ref) {
+		name = path.Join(path.Dir(from), ref)
+	}
+	name = path.Clean(name)
+	content, ok := m[name]
+	if !ok {
+		return name, nil, fmt.Errorf("%v not found", name)
+	}
+	return name, []byte(content), nil
+}
+
+// Insert is an «insert» block, with the document it referenced
+type Insert struct {
+	// Name of the inserted document, as given by the Resolver
+	Name string
+	// Document is the inserted document; its indexes are from the start of that document
+	Document *Document
+	Headers  []KeyVal
+	Index    int64
+}
+
+// InsertError is an error in an inserted document
+type InsertError struct {
+	// Name of the document the error is in
+	Name string
+	Err  error
+}
+
+func (err InsertError) Error() string {
+	return fmt.Sprintf("%v:%v", err.Name, err.Err)
+}
+
+func (err InsertError) Unwrap() error { return err.Err }
+
+// Inserter parses documents and the documents they insert
+type Inserter struct {
+	Resolver Resolver
+	// MaxDepth is how deep inserts may be nested, zero means DefaultInsertDepth
+	MaxDepth int
+	// Handlers are the handlers for the other blocks, nil means DefaultBlockHandlers
+	Handlers BlockHandlers
+}
+
+// Document resolves and parses the document named name, along with any documents it inserts.
+// Errors from a document are an InsertError with the name of that document.
+func (ins Inserter) Document(name string) (*Document, error) {
+	name, content, err := ins.Resolver.Resolve("", name)
+	if err != nil {
+		return nil, err
+	}
+	return ins.parse(name, content, nil)
+}
+
+// parse parses the content of the document name; chain are the documents that inserted it
+func (ins Inserter) parse(name string, content []byte, chain []string) (*Document, error) {
+	chain = append(chain[:len(chain):len(chain)], name)
+	state, _ := input.Bytes(ParseDocumentWith(ins.handlers(chain)), content, input.Auto)
+	if state.IsError {
+		err := state.Err
+		if _, ok := err.(PositionError); !ok {
+			err = positionError(state, err)
+		}
+		return nil, InsertError{Name: name, Err: err}
+	}
+	return state.Result.(*Document), nil
+}
+
+// handlers returns the handlers for a document, where inserts are resolved from the last document of the chain
+func (ins Inserter) handlers(chain []string) BlockHandlers {
+	base := ins.Handlers
+	if base == nil {
+		base = DefaultBlockHandlers
+	}
+	handlers := make(BlockHandlers, len(base)+1)
+	for typ, handler := range base {
+		handlers[typ] = handler
+	}
+	handlers[InsertType] = func(block Block, _ BlockHandlers) (interface{}, error) {
+		return ins.insert(block, chain)
+	}
+	return handlers
+}
+
+func (ins Inserter) insert(block Block, chain []string) (interface{}, error) {
+	ref, ok := block.Header("file")
+	if !ok || ref == "" {
+		return nil, errors.New("expected a file header")
+	}
+	maxDepth := ins.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultInsertDepth
+	}
+	if len(chain) > maxDepth {
+		return nil, fmt.Errorf("inserts nested more than %v deep", maxDepth)
+	}
+	name, content, err := ins.Resolver.Resolve(chain[len(chain)-1], ref)
+	if err != nil {
+		return nil, err
+	}
+	for i := range chain {
+		if chain[i] == name {
+			return nil, fmt.Errorf("insert cycle %v", strings.Join(append(chain[i:len(chain):len(chain)], name), " -> "))
+		}
+	}
+	doc, err := ins.parse(name, content, chain)
+	if err != nil {
+		return nil, err
+	}
+	return Insert{
+		Name:     name,
+		Document: doc,
+		Headers:  block.Headers,
+		Index:    block.Index,
+	}, nil
+}
@@ -0,0 +1,119 @@
+package gdtxt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInserter(t *testing.T) {
+	ins := Inserter{Resolver: MapResolver{
+		"main.gdtxt":         "intro\n\n«insert | file : chapters/one.gdtxt ; »\n",
+		"chapters/one.gdtxt": "one\n\n«insert | file : two.gdtxt ; »\n",
+		"chapters/two.gdtxt": "two\n",
+	}}
+	doc, err := ins.Document("main.gdtxt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	one, ok := doc.Elements[1].(Insert)
+	if !ok || one.Name != "chapters/one.gdtxt" {
+		t.Fatalf("expected an insert of chapters/one.gdtxt got %#v", doc.Elements[1])
+	}
+	two, ok := one.Document.Elements[1].(Insert)
+	if !ok || two.Name != "chapters/two.gdtxt" {
+		t.Fatalf("expected an insert of chapters/two.gdtxt got %#v", one.Document.Elements[1])
+	}
+	if _, ok := two.Document.Elements[0].(Paragraph); !ok {
+		t.Errorf("expected a paragraph got %#v", two.Document.Elements[0])
+	}
+}
+
+func TestInserterErrors(t *testing.T) {
+	resolver := MapResolver{
+		"self.gdtxt":    "«insert | file : self.gdtxt ; »\n",
+		"a.gdtxt":       "a\n\n«insert | file : dir/b.gdtxt ; »\n",
+		"dir/b.gdtxt":   "b\n\n«insert | file : ../a.gdtxt ; »\n",
+		"bad.gdtxt":     "x\n\n«insert | file : broken.gdtxt ; »\n",
+		"broken.gdtxt":  "ok\n\nfine\n\n«list | type : bogus ; • a»\n",
+		"missing.gdtxt": "«insert | file : nope.gdtxt ; »\n",
+		"deep0.gdtxt":   "«insert | file : deep1.gdtxt ; »\n",
+		"deep1.gdtxt":   "«insert | file : deep2.gdtxt ; »\n",
+		"deep2.gdtxt":   "«insert | file : deep3.gdtxt ; »\n",
+		"deep3.gdtxt":   "deep\n",
+	}
+	tests := map[string]struct {
+		name     string
+		maxDepth int
+		err      string
+	}{
+		"self cycle": {
+			name: "self.gdtxt",
+			err:  "self.gdtxt:1:1: insert block: insert cycle self.gdtxt -> self.gdtxt",
+		},
+		"cycle": {
+			name: "a.gdtxt",
+			err:  "a.gdtxt:3:1: insert block: dir/b.gdtxt:3:1: insert block: insert cycle a.gdtxt -> dir/b.gdtxt -> a.gdtxt",
+		},
+		"error in inserted document": {
+			name: "bad.gdtxt",
+			err:  `bad.gdtxt:3:1: insert block: broken.gdtxt:5:7: list block: invalid type header "bogus": expected ordered, unordered or checkbox`,
+		},
+		"not found": {
+			name: "missing.gdtxt",
+			err:  "missing.gdtxt:1:1: insert block: nope.gdtxt not found",
+		},
+		"depth limit": {
+			// deep0 inserts deep1 inserts deep2, which can not insert deep3
+			name:     "deep0.gdtxt",
+			maxDepth: 2,
+			err:      "deep0.gdtxt:1:1: insert block: deep1.gdtxt:1:1: insert block: deep2.gdtxt:1:1: insert block: inserts nested more than 2 deep",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ins := Inserter{Resolver: resolver, MaxDepth: tc.maxDepth}
+			_, err := ins.Document(tc.name)
+			if err == nil {
+				t.Fatalf("expected error %q", tc.err)
+			}
+			if err.Error() != tc.err {
+				t.Errorf("expected %q\ngot      %q", tc.err, err)
+			}
+			var ierr InsertError
+			if !errors.As(err, &ierr) || ierr.Name != tc.name {
+				t.Errorf("expected an InsertError for %v got %#v", tc.name, err)
+			}
+		})
+	}
+
+	// within the depth limit
+	ins := Inserter{Resolver: resolver, MaxDepth: 3}
+	if _, err := ins.Document("deep0.gdtxt"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestInserterErrorPosition(t *testing.T) {
+	// the error is mapped to the line and column in the inserted document
+	ins := Inserter{Resolver: MapResolver{
+		"main.gdtxt": "§ Main\n\n«insert | file : part.gdtxt ; »\n",
+		"part.gdtxt": "one\r\n\r\n§ Part\r\n\r\n«list | type : bogus ; • a»\r\n",
+	}}
+	_, err := ins.Document("main.gdtxt")
+	var ierr InsertError
+	if !errors.As(err, &ierr) {
+		t.Fatalf("expected an InsertError got %v", err)
+	}
+	var inner InsertError
+	if !errors.As(ierr.Err, &inner) || inner.Name != "part.gdtxt" {
+		t.Fatalf("expected an InsertError for part.gdtxt got %v", ierr.Err)
+	}
+	var perr PositionError
+	if !errors.As(inner.Err, &perr) || perr.Line != 5 || perr.Column != 7 {
+		t.Errorf("expected part.gdtxt:5:7 got %v", inner)
+	}
+	if !strings.HasPrefix(err.Error(), "main.gdtxt:3:1: ") {
+		t.Errorf("expected the error at main.gdtxt:3:1 got %v", err)
+	}
+}
@@ -0,0 +1,306 @@
+package gdtxt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdey/ppc/parse"
+	"github.com/gdey/ppc/parse/grapheme"
+)
+
+// A list block has an item on each line, starting with any of the list markers ("•", "•[ ]",
+// "•[x]", "#", "1."). Items are nested by indenting them, and lines without a marker continue
+// the item before them.
+//
+//	«list | type : ordered | style : roman | start : 4 ;
+//	• first
+//	    • sub item
+//	      that goes on
+//	• second
+//	»
+//
+// Headers:
+//    type  : unordered (default), ordered, or checkbox
+//    style : the numbering of ordered lists; decimal (default), alpha, upper-alpha, roman, upper-roman
+//    start : the number of the first item of an ordered list, nested lists start at 1
+
+type ListType uint8
+
+const (
+	ListUnordered ListType = iota
+	ListOrdered
+	ListCheckbox
+)
+
+var listTypeNames = [...]string{
+	ListUnordered: "unordered",
+	ListOrdered:   "ordered",
+	ListCheckbox:  "checkbox",
+}
+
+func (typ ListType) String() string {
+	if int(typ) < len(listTypeNames) {
+		return listTypeNames[typ]
+	}
+	return "unknown"
+}
+
+type NumberStyle uint8
+
+const (
+	NumberDecimal NumberStyle = iota
+	NumberAlpha
+	NumberUpperAlpha
+	NumberRoman
+	NumberUpperRoman
+)
+
+var numberStyleNames = [...]string{
+	NumberDecimal:    "decimal",
+	NumberAlpha:      "alpha",
+	NumberUpperAlpha: "upper-alpha",
+	NumberRoman:      "roman",
+	NumberUpperRoman: "upper-roman",
+}
+
+func (style NumberStyle) String() string {
+	if int(style) < len(numberStyleNames) {
+		return numberStyleNames[style]
+	}
+	return "unknown"
+}
+
+// Format returns n in the style; e.g. 4 is "4", "d", "D", "iv", or "IV".
+// Alpha numbers go "z", "aa", "ab"... and numbers that can not be written in the style
+// (less than 1, or roman numbers over 3999) are written as decimal.
+func (style NumberStyle) Format(n int) string {
+	switch {
+	case style == NumberDecimal || n < 1:
+		return strconv.Itoa(n)
+	case style == NumberAlpha || style == NumberUpperAlpha:
+		var b []byte
+		for ; n > 0; n = (n - 1) / 26 {
+			b = append([]byte{byte('a' + (n-1)%26)}, b...)
+		}
+		if style == NumberUpperAlpha {
+			return strings.ToUpper(string(b))
+		}
+		return string(b)
+	case (style == NumberRoman || style == NumberUpperRoman) && n < 4000:
+		s := roman(n)
+		if style == NumberRoman {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+	return strconv.Itoa(n)
+}
+
+var romanNumerals = []struct {
+	value  int
+	symbol string
+}{
+	{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"},
+	{100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"},
+	{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+}
+
+func roman(n int) string {
+	var b strings.Builder
+	for _, numeral := range romanNumerals {
+		for ; n >= numeral.value; n -= numeral.value {
+			b.WriteString(numeral.symbol)
+		}
+	}
+	return b.String()
+}
+
+// ListBlock is a «list» block
+type ListBlock struct {
+	Type    ListType
+	Style   NumberStyle
+	Start   int
+	Items   []ListBlockItem
+	Headers []KeyVal
+	Index   int64
+}
+
+// ListBlockItem is an item of a ListBlock
+type ListBlockItem struct {
+	Text string
+	// Number of the item in an ordered list, and Label is the number in the list's style
+	Number int
+	Label  string
+	// Checkbox is set for the items of a checkbox list, or items with a "•[ ]" marker
+	Checkbox bool
+	Checked  bool
+	// Items are the nested items
+	Items []ListBlockItem
+}
+
+// listBlockLine matches the indentation, marker(s) and text of an item
+var listBlockLine = parse.SequenceOf(
+	parse.Map(
+		parse.Optional(ParseWordWhitespace),
+		func(r interface{}) interface{} {
+			if ws, ok := r.(WordWhitespace); ok {
+				return ws.Text
+			}
+			return ""
+		},
+	),
+	parse.Many1(ParseListMarker),
+	parse.Optional(matchStringTillEndOfLine),
+	parse.EndOfInput(),
+)
+
+type listBlockEntry struct {
+	indent int
+	item   ListBlockItem
+}
+
+// ListBlockHandler handles «list» blocks
+// result is ListBlock
+func ListBlockHandler(block Block, _ BlockHandlers) (interface{}, error) {
+	list := ListBlock{
+		Start:   1,
+		Headers: block.Headers,
+		Index:   block.Index,
+	}
+	if err := list.setHeaders(block.Headers); err != nil {
+		return nil, err
+	}
+
+	var entries []listBlockEntry
+	body := trimFirstNewline(block.UnescapeBody())
+	for i, line := range splitLines(body) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		state := parse.String(listBlockLine, line)
+		if state.IsError {
+			// continues the item before it
+			if len(entries) == 0 {
+				return nil, fmt.Errorf("expected a list item on line %v of the body", i+1)
+			}
+			item := &entries[len(entries)-1].item
+			item.Text = strings.TrimSpace(item.Text + " " + strings.TrimSpace(line))
+			continue
+		}
+		results := state.Result.([]interface{})
+		markers := results[1].([]interface{})
+		marker := markers[len(markers)-1].(string)
+		text, _ := results[2].(string)
+		entries = append(entries, listBlockEntry{
+			indent: grapheme.Column(results[0].(string), grapheme.DefaultTabWidth),
+			item: ListBlockItem{
+				Text:     text,
+				Checkbox: list.Type == ListCheckbox || strings.HasPrefix(marker, "•["),
+				Checked:  marker == "•[X]",
+			},
+		})
+	}
+	if len(entries) == 0 {
+		return list, nil
+	}
+	for i := 0; i < len(entries); {
+		// entries indented less than the ones before them start a new run of items
+		var items []ListBlockItem
+		items, i = nestListBlockItems(entries, i)
+		list.Items = append(list.Items, items...)
+	}
+	list.number(list.Items, list.Start)
+	return list, nil
+}
+
+func (list *ListBlock) setHeaders(headers []KeyVal) error {
+	for _, header := range headers {
+		val := strings.ToLower(header.Val)
+		switch strings.ToLower(header.Key) {
+		case "type":
+			typ, ok := lookupName(listTypeNames[:], val)
+			if !ok {
+				return HeaderError{Header: header, Err: errors.New("expected ordered, unordered or checkbox")}
+			}
+			list.Type = ListType(typ)
+		case "style":
+			style, ok := lookupName(numberStyleNames[:], val)
+			if !ok {
+				return HeaderError{Header: header, Err: fmt.Errorf("expected one of %v", strings.Join(numberStyleNames[:], ", "))}
+			}
+			list.Style = NumberStyle(style)
+		case "start":
+			start, err := strconv.Atoi(val)
+			if err != nil {
+				return HeaderError{Header: header, Err: errors.New("expected a number")}
+			}
+			list.Start = start
+		}
+	}
+	return nil
+}
+
+func lookupName(names []string, name string) (int, bool) {
+	for i := range names {
+		if names[i] == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// nestListBlockItems makes the entries that are indented more than the entry at i
+// the items of the entry before them. It stops at the first entry indented less than
+// the entry at i, and returns its index.
+func nestListBlockItems(entries []listBlockEntry, i int) ([]ListBlockItem, int) {
+	indent := entries[i].indent
+	var items []ListBlockItem
+	for i < len(entries) && entries[i].indent >= indent {
+		if entries[i].indent == indent || len(items) == 0 {
+			items = append(items, entries[i].item)
+			i++
+			continue
+		}
+		var children []ListBlockItem
+		children, i = nestListBlockItems(entries, i)
+		last := &items[len(items)-1]
+		last.Items = append(last.Items, children...)
+	}
+	return items, i
+}
+
+// number sets the Number and Label of the items of an ordered list
+func (list ListBlock) number(items []ListBlockItem, start int) {
+	if list.Type != ListOrdered {
+		return
+	}
+	for i := range items {
+		items[i].Number = start + i
+		items[i].Label = list.Style.Format(start + i)
+		list.number(items[i].Items, 1)
+	}
+}
+
+// splitLines splits s on any of the newlines
+func splitLines(s string) []string {
+	var lines []string
+	for len(s) > 0 {
+		i := strings.IndexFunc(s, parse.IsNewline)
+		if i == -1 {
+			lines = append(lines, s)
+			break
+		}
+		lines = append(lines, s[:i])
+		s = s[i:]
+		if strings.HasPrefix(s, "\r\n") {
+			s = s[2:]
+			continue
+		}
+		_, n := utf8.DecodeRuneInString(s)
+		s = s[n:]
+	}
+	return lines
+}
@@ -0,0 +1,110 @@
+package gdtxt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// listBlockTexts returns the text of the items, with the texts of their items after them in brackets
+func listBlockTexts(items []ListBlockItem) []interface{} {
+	var texts []interface{}
+	for _, item := range items {
+		texts = append(texts, item.Text)
+		if len(item.Items) > 0 {
+			texts = append(texts, listBlockTexts(item.Items))
+		}
+	}
+	return texts
+}
+
+func TestListBlockHandler(t *testing.T) {
+	tests := map[string]struct {
+		body  string
+		texts []interface{}
+	}{
+		"flat": {
+			body:  "• a\n• b\n• c",
+			texts: []interface{}{"a", "b", "c"},
+		},
+		"nested": {
+			body:  "• a\n    • a1\n    • a2\n• b",
+			texts: []interface{}{"a", []interface{}{"a1", "a2"}, "b"},
+		},
+		"deeper then back": {
+			body:  "• a\n  • a1\n    • a1x\n  • a2\n• b",
+			texts: []interface{}{"a", []interface{}{"a1", []interface{}{"a1x"}, "a2"}, "b"},
+		},
+		"first indented": {
+			body:  "    • a\n• b\n• c",
+			texts: []interface{}{"a", "b", "c"},
+		},
+		"first indented with items": {
+			body:  "    • a\n        • a1\n• b\n    • b1\n• c",
+			texts: []interface{}{"a", []interface{}{"a1"}, "b", []interface{}{"b1"}, "c"},
+		},
+		"dedent between levels": {
+			body:  "• a\n    • a1\n  • a2\n• b",
+			texts: []interface{}{"a", []interface{}{"a1", "a2"}, "b"},
+		},
+		"continued": {
+			body:  "• a\n  goes on\n• b",
+			texts: []interface{}{"a goes on", "b"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := ListBlockHandler(Block{Type: "list", Body: tc.body}, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			texts := listBlockTexts(result.(ListBlock).Items)
+			if !reflect.DeepEqual(texts, tc.texts) {
+				t.Errorf("expected %q got %q", tc.texts, texts)
+			}
+		})
+	}
+}
+
+func TestListBlockNumbers(t *testing.T) {
+	block := Block{
+		Type:    "list",
+		Headers: []KeyVal{{Key: "type", Val: "ordered"}, {Key: "style", Val: "roman"}, {Key: "start", Val: "4"}},
+		Body:    "  • a\n• b\n    • b1\n• c",
+	}
+	result, err := ListBlockHandler(block, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	items := result.(ListBlock).Items
+	var labels []string
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	if expected := []string{"iv", "v", "vi"}; !reflect.DeepEqual(labels, expected) {
+		t.Errorf("expected %q got %q", expected, labels)
+	}
+	if len(items[1].Items) != 1 || items[1].Items[0].Label != "i" {
+		t.Errorf("expected b1 numbered i, got %#v", items[1].Items)
+	}
+}
+
+func TestListBlockDocument(t *testing.T) {
+	doc := parseDocument(t, "«list | type : ordered | page-break: keep-together ;\n• a\n• b\n»\n")
+	if len(doc.Elements) != 1 {
+		t.Fatalf("expected one element, got %#v", doc.Elements)
+	}
+	list, ok := doc.Elements[0].(ListBlock)
+	if !ok {
+		t.Fatalf("expected a ListBlock, got %T", doc.Elements[0])
+	}
+	if list.Type != ListOrdered {
+		t.Errorf("expected an ordered list, got %v", list.Type)
+	}
+	expected := []KeyVal{{Key: "type", Val: "ordered", Index: 7}, {Key: "page-break", Val: "keep-together", Index: 24}}
+	if !reflect.DeepEqual(list.Headers, expected) {
+		t.Errorf("expected headers %#v got %#v", expected, list.Headers)
+	}
+	if texts := listBlockTexts(list.Items); !reflect.DeepEqual(texts, []interface{}{"a", "b"}) {
+		t.Errorf("expected items a and b, got %q", texts)
+	}
+}
@@ -0,0 +1,170 @@
+package gdtxt
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gdey/ppc/ast"
+)
+
+// MarkerKind is the kind of marker a list line starts with
+type MarkerKind uint8
+
+const (
+	// MarkerBullet is "•"
+	MarkerBullet MarkerKind = iota
+	// MarkerNumbered is a number followed by a "." e.g. "1." or "1.2."
+	MarkerNumbered
+	// MarkerCheckbox is "•[ ]" or "•[x]"
+	MarkerCheckbox
+	// MarkerHash is "#"
+	MarkerHash
+)
+
+var markerKindNames = [...]string{
+	MarkerBullet:   "bullet",
+	MarkerNumbered: "numbered",
+	MarkerCheckbox: "checkbox",
+	MarkerHash:     "hash",
+}
+
+func (kind MarkerKind) String() string {
+	if int(kind) < len(markerKindNames) {
+		return markerKindNames[kind]
+	}
+	return "unknown"
+}
+
+// ListMarker is the typed form of the LevelText of a List
+type ListMarker struct {
+	Kind MarkerKind
+	// Number is the path of a numbered marker, e.g. [1 2] for "1.2."
+	Number []int
+	// Checked is set for a checked checkbox
+	Checked bool
+}
+
+// NewListMarker returns the marker for the LevelText of a List. The kind is the kind of the
+// last marker, and the numbers of all the numbered markers make up the Number.
+func NewListMarker(levelText []string) ListMarker {
+	var marker ListMarker
+	for _, text := range levelText {
+		switch {
+		case text == "•":
+			marker.Kind = MarkerBullet
+		case text == "#":
+			marker.Kind = MarkerHash
+		case strings.HasPrefix(text, "•["):
+			marker.Kind = MarkerCheckbox
+			marker.Checked = text == "•[X]"
+		default:
+			marker.Kind = MarkerNumbered
+			n, _ := strconv.Atoi(strings.TrimSuffix(text, "."))
+			marker.Number = append(marker.Number, n)
+		}
+	}
+	return marker
+}
+
+// ListTree is a run of List lines nested by their Level
+type ListTree struct {
+	Items []ListNode
+	Index int64
+	// End is the index after the text of the last line
+	End int64
+}
+
+func (tree ListTree) Span() ast.Span       { return ast.Span{Start: tree.Index, End: tree.End} }
+func (tree ListTree) Children() []ast.Node { return listNodes(tree.Items) }
+
+// ListNode is a List line and the lines nested under it
+type ListNode struct {
+	Marker ListMarker
+	Text   string
+	Index  int64
+	// End is the index after the text of the line, or of the last line nested under it
+	End   int64
+	Items []ListNode
+}
+
+func (node ListNode) Span() ast.Span       { return ast.Span{Start: node.Index, End: node.End} }
+func (node ListNode) Children() []ast.Node { return listNodes(node.Items) }
+
+func listNodes(items []ListNode) []ast.Node {
+	if len(items) == 0 {
+		return nil
+	}
+	children := make([]ast.Node, len(items))
+	for i := range items {
+		children[i] = items[i]
+	}
+	return children
+}
+
+// GroupLists replaces each run of List elements with a ListTree; nil elements (blank lines)
+// within a run are dropped.
+func GroupLists(elements []interface{}) []interface{} {
+	var (
+		grouped []interface{}
+		run     []List
+	)
+	endRun := func() {
+		if len(run) == 0 {
+			return
+		}
+		tree := ListTree{Index: run[0].Index, End: run[len(run)-1].End}
+		for i := 0; i < len(run); {
+			// lines with a lower level than the ones before them start a new run of items
+			var items []ListNode
+			items, i = nestListNodes(run, i)
+			tree.Items = append(tree.Items, items...)
+		}
+		grouped = append(grouped, tree)
+		run = nil
+	}
+	for i, element := range elements {
+		switch element := element.(type) {
+		case List:
+			run = append(run, element)
+		case nil:
+			if len(run) > 0 && i+1 < len(elements) {
+				if _, ok := elements[i+1].(List); ok {
+					continue
+				}
+			}
+			endRun()
+			grouped = append(grouped, element)
+		default:
+			endRun()
+			grouped = append(grouped, element)
+		}
+	}
+	endRun()
+	return grouped
+}
+
+// nestListNodes makes the lines with a higher level than the line at i
+// the items of the line before them. It stops at the first line with a lower
+// level than the line at i, and returns its index.
+func nestListNodes(lines []List, i int) ([]ListNode, int) {
+	level := lines[i].Level
+	var items []ListNode
+	for i < len(lines) && lines[i].Level >= level {
+		if lines[i].Level == level || len(items) == 0 {
+			items = append(items, ListNode{
+				Marker: NewListMarker(lines[i].LevelText),
+				Text:   lines[i].Text,
+				Index:  lines[i].Index,
+				End:    lines[i].End,
+			})
+			i++
+			continue
+		}
+		var children []ListNode
+		children, i = nestListNodes(lines, i)
+		last := &items[len(items)-1]
+		last.Items = append(last.Items, children...)
+		last.End = children[len(children)-1].End
+	}
+	return items, i
+}
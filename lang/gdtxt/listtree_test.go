@@ -0,0 +1,78 @@
+package gdtxt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// listNodeTexts returns the text of the nodes, with the texts of their items after them in brackets
+func listNodeTexts(nodes []ListNode) []interface{} {
+	var texts []interface{}
+	for _, node := range nodes {
+		texts = append(texts, node.Text)
+		if len(node.Items) > 0 {
+			texts = append(texts, listNodeTexts(node.Items))
+		}
+	}
+	return texts
+}
+
+func TestGroupLists(t *testing.T) {
+	tests := map[string]struct {
+		text  string
+		texts []interface{}
+	}{
+		"flat": {
+			text:  "• a\n\n• b\n\n",
+			texts: []interface{}{"a", "b"},
+		},
+		"nested": {
+			text:  "1. a\n\n1.1. a1\n\n1.2. a2\n\n2. b\n\n",
+			texts: []interface{}{"a", []interface{}{"a1", "a2"}, "b"},
+		},
+		"sub first": {
+			text:  "1.1. sub first\n\n2. top\n\n",
+			texts: []interface{}{"sub first", "top"},
+		},
+		"sub first with items": {
+			text:  "•• a\n\n••• a1\n\n• b\n\n•• b1\n\n",
+			texts: []interface{}{"a", []interface{}{"a1"}, "b", []interface{}{"b1"}},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			doc := parseDocument(t, tc.text)
+			if len(doc.Elements) != 1 {
+				t.Fatalf("expected one list got %#v", doc.Elements)
+			}
+			tree, ok := doc.Elements[0].(ListTree)
+			if !ok {
+				t.Fatalf("expected a ListTree got %#v", doc.Elements[0])
+			}
+			texts := listNodeTexts(tree.Items)
+			if !reflect.DeepEqual(texts, tc.texts) {
+				t.Errorf("expected %q got %q", tc.texts, texts)
+			}
+		})
+	}
+}
+
+func TestNewListMarker(t *testing.T) {
+	tests := map[string]struct {
+		levelText []string
+		marker    ListMarker
+	}{
+		"bullet":    {levelText: []string{"•"}, marker: ListMarker{Kind: MarkerBullet}},
+		"numbered":  {levelText: []string{"1.", "2."}, marker: ListMarker{Kind: MarkerNumbered, Number: []int{1, 2}}},
+		"checked":   {levelText: []string{"•[X]"}, marker: ListMarker{Kind: MarkerCheckbox, Checked: true}},
+		"unchecked": {levelText: []string{"•[]"}, marker: ListMarker{Kind: MarkerCheckbox}},
+		"hash":      {levelText: []string{"1.", "#"}, marker: ListMarker{Kind: MarkerHash, Number: []int{1}}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if marker := NewListMarker(tc.levelText); !reflect.DeepEqual(marker, tc.marker) {
+				t.Errorf("expected %#v got %#v", tc.marker, marker)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package gdtxt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gdey/ppc/parse"
+)
+
+func TestParseWords(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		words []interface{}
+	}{
+		"words": {
+			input: "héllo wörld",
+			words: []interface{}{
+				WordCharacters{Index: 0, Text: "héllo"},
+				WordWhitespace{Index: 6, Text: " "},
+				WordCharacters{Index: 7, Text: "wörld"},
+			},
+		},
+		// the characters of a word stop at a "[", so it can start an inline style
+		"open bracket": {
+			input: "a[b c",
+			words: []interface{}{
+				WordCharacters{Index: 0, Text: "a"},
+				WordCharacters{Index: 1, Text: "["},
+				WordCharacters{Index: 2, Text: "b"},
+				WordWhitespace{Index: 3, Text: " "},
+				WordCharacters{Index: 4, Text: "c"},
+			},
+		},
+		"trailing bracket": {
+			input: "ab[",
+			words: []interface{}{
+				WordCharacters{Index: 0, Text: "ab"},
+				WordCharacters{Index: 2, Text: "["},
+			},
+		},
+		// and at a "\", so it can start an escape
+		"escape": {
+			input: `a\[b`,
+			words: []interface{}{
+				WordCharacters{Index: 0, Text: "a"},
+				WordEscaped{Index: 1, Text: "["},
+				WordCharacters{Index: 3, Text: "b"},
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.String(parse.Many(ParseWord), tc.input)
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			if !reflect.DeepEqual(state.Result, tc.words) {
+				t.Errorf("expected %#v got %#v", tc.words, state.Result)
+			}
+		})
+	}
+}
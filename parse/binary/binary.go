@@ -0,0 +1,352 @@
+/*
+Package binary has parsers for binary formats; fixed width integers, varints, magic numbers,
+and length or count prefixed data. Since the parse.State.Source is an io.ReaderAt the parsers
+can jump around the input with Seek and At.
+
+	chunk := parse.Chain(
+		binary.Uint32BE(),
+		func(length interface{}) parse.Parser {
+			return parse.SequenceOf(
+				binary.Bytes(4), // type
+				binary.Bytes(int(length.(uint32))),
+				binary.Uint32BE(), // crc
+			)
+		},
+	)
+	png := parse.SequenceOf(
+		binary.Magic([]byte("\x89PNG\r\n\x1a\n")),
+		parse.Many1(chunk),
+		parse.EndOfInput(),
+	)
+*/
+package binary
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gdey/ppc/parse"
+)
+
+// Bytes matches the next n bytes
+// result is []byte
+func Bytes(n int) parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		if state.Bit != 0 {
+			return notAligned(state)
+		}
+		if n < 0 {
+			return state.WithError(fmt.Errorf("invalid byte count %v at %v", n, state.Index))
+		}
+		buff, nn, err := state.ReadNextBytes(n)
+		if nn != n {
+			return state.WithError(fmt.Errorf("expected %v bytes at %v: %v", n, state.Index, err))
+		}
+		return state.WithResult(buff, state.Index+int64(n))
+	})
+}
+
+// Magic matches the exact bytes, e.g. a file signature
+// result is []byte
+func Magic(magic []byte) parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		if state.Bit != 0 {
+			return notAligned(state)
+		}
+		buff, n, _ := state.ReadNextBytes(len(magic))
+		if n != len(magic) || !bytes.Equal(buff, magic) {
+			return state.WithError(fmt.Errorf("expected magic % x at %v", magic, state.Index))
+		}
+		return state.WithResult(buff, state.Index+int64(n))
+	})
+}
+
+func fixed(size int, fn func([]byte) interface{}) parse.Parser {
+	return parse.Map(Bytes(size), func(r interface{}) interface{} { return fn(r.([]byte)) })
+}
+
+// Uint8 matches one byte
+// result is uint8
+func Uint8() parse.Parser {
+	return fixed(1, func(b []byte) interface{} { return b[0] })
+}
+
+// Int8 matches one byte as a signed integer
+// result is int8
+func Int8() parse.Parser {
+	return fixed(1, func(b []byte) interface{} { return int8(b[0]) })
+}
+
+// Uint16LE matches a little endian uint16
+// result is uint16
+func Uint16LE() parse.Parser { return Uint16(binary.LittleEndian) }
+
+// Uint16BE matches a big endian uint16
+// result is uint16
+func Uint16BE() parse.Parser { return Uint16(binary.BigEndian) }
+
+// Uint32LE matches a little endian uint32
+// result is uint32
+func Uint32LE() parse.Parser { return Uint32(binary.LittleEndian) }
+
+// Uint32BE matches a big endian uint32
+// result is uint32
+func Uint32BE() parse.Parser { return Uint32(binary.BigEndian) }
+
+// Uint64LE matches a little endian uint64
+// result is uint64
+func Uint64LE() parse.Parser { return Uint64(binary.LittleEndian) }
+
+// Uint64BE matches a big endian uint64
+// result is uint64
+func Uint64BE() parse.Parser { return Uint64(binary.BigEndian) }
+
+// Uint16 matches a uint16 in the given byte order
+// result is uint16
+func Uint16(order binary.ByteOrder) parse.Parser {
+	return fixed(2, func(b []byte) interface{} { return order.Uint16(b) })
+}
+
+// Uint32 matches a uint32 in the given byte order
+// result is uint32
+func Uint32(order binary.ByteOrder) parse.Parser {
+	return fixed(4, func(b []byte) interface{} { return order.Uint32(b) })
+}
+
+// Uint64 matches a uint64 in the given byte order
+// result is uint64
+func Uint64(order binary.ByteOrder) parse.Parser {
+	return fixed(8, func(b []byte) interface{} { return order.Uint64(b) })
+}
+
+// Int16 matches a two's complement int16 in the given byte order
+// result is int16
+func Int16(order binary.ByteOrder) parse.Parser {
+	return fixed(2, func(b []byte) interface{} { return int16(order.Uint16(b)) })
+}
+
+// Int32 matches a two's complement int32 in the given byte order
+// result is int32
+func Int32(order binary.ByteOrder) parse.Parser {
+	return fixed(4, func(b []byte) interface{} { return int32(order.Uint32(b)) })
+}
+
+// Int64 matches a two's complement int64 in the given byte order
+// result is int64
+func Int64(order binary.ByteOrder) parse.Parser {
+	return fixed(8, func(b []byte) interface{} { return int64(order.Uint64(b)) })
+}
+
+// byteReader reads the bytes of the state one at a time
+type byteReader struct {
+	state parse.State
+}
+
+func (r *byteReader) ReadByte() (byte, error) {
+	buff, n, err := r.state.ReadNextBytes(1)
+	if n != 1 {
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	r.state.Index++
+	return buff[0], nil
+}
+
+// Uvarint matches an unsigned varint, as encoded by encoding/binary.PutUvarint
+// result is uint64
+func Uvarint() parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		if state.Bit != 0 {
+			return notAligned(state)
+		}
+		r := byteReader{state: state}
+		v, err := binary.ReadUvarint(&r)
+		if err != nil {
+			return state.WithError(fmt.Errorf("invalid uvarint at %v: %v", state.Index, err))
+		}
+		return state.WithResult(v, r.state.Index)
+	})
+}
+
+// Varint matches a signed (zig-zag) varint, as encoded by encoding/binary.PutVarint
+// result is int64
+func Varint() parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		if state.Bit != 0 {
+			return notAligned(state)
+		}
+		r := byteReader{state: state}
+		v, err := binary.ReadVarint(&r)
+		if err != nil {
+			return state.WithError(fmt.Errorf("invalid varint at %v: %v", state.Index, err))
+		}
+		return state.WithResult(v, r.state.Index)
+	})
+}
+
+// AsInt converts the result of one of the integer parsers to an int64
+func AsInt(r interface{}) (int64, bool) {
+	switch v := r.(type) {
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		if v > 1<<63-1 {
+			return 0, false
+		}
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// limitTo returns a state where the source ends at end, so parsers
+// can not read past it.
+func limitTo(state parse.State, end int64) parse.State {
+	limited := state
+	limited.Source = io.NewSectionReader(state.Source, 0, end)
+	return limited
+}
+
+// LengthPrefixed reads a length with the length parser, and then applies the body parser
+// to exactly that many bytes. The body may not read past the end of the data, and must
+// consume all of it. If body is nil, the data is returned as []byte.
+// result is the result of body
+func LengthPrefixed(length parse.Parser, body parse.Parser) parse.Parser {
+	if body == nil {
+		return parse.Chain(length, func(r interface{}) parse.Parser {
+			n, ok := AsInt(r)
+			if !ok || n > int64(int(^uint(0)>>1)) {
+				return fail(fmt.Errorf("invalid length %v", r))
+			}
+			return Bytes(int(n))
+		})
+	}
+	return parse.Func(func(state parse.State) parse.State {
+		next := length.Run(state)
+		if next.IsError {
+			return next
+		}
+		n, ok := AsInt(next.Result)
+		if !ok || n < 0 {
+			return state.WithError(fmt.Errorf("invalid length %v at %v", next.Result, state.Index))
+		}
+		end := next.Index + n
+		result := body.Run(limitTo(next, end))
+		if result.IsError {
+			return state.WithError(result.Err)
+		}
+		if result.Index != end {
+			return state.WithError(fmt.Errorf("expected data to end at %v not %v", end, result.Index))
+		}
+		// put back the original source
+		result.Source = state.Source
+		return result
+	})
+}
+
+// CountPrefixed reads a count with the count parser, then applies the item parser that many times
+// result is []interface{}
+func CountPrefixed(count parse.Parser, item parse.Parser) parse.Parser {
+	return parse.Chain(count, func(r interface{}) parse.Parser {
+		n, ok := AsInt(r)
+		if !ok || n < 0 || n > int64(int(^uint(0)>>1)) {
+			return fail(fmt.Errorf("invalid count %v", r))
+		}
+		return parse.ApplyN(int(n), item)
+	})
+}
+
+func fail(err error) parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		return state.WithError(err)
+	})
+}
+
+// Align skips bytes till the index is a multiple of n
+// result is nil
+func Align(n int64) parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		if state.Bit != 0 {
+			return notAligned(state)
+		}
+		if n <= 0 {
+			return state.WithError(errors.New("alignment must be positive"))
+		}
+		rem := state.Index % n
+		if rem == 0 {
+			return state.WithResult(nil, state.Index)
+		}
+		return Skip(n - rem).Run(state)
+	})
+}
+
+// Skip skips the next n bytes
+// result is nil
+func Skip(n int64) parse.Parser {
+	return parse.Discard(parse.Func(func(state parse.State) parse.State {
+		if n < 0 {
+			return state.WithError(fmt.Errorf("can not skip %v bytes", n))
+		}
+		if state.Bit != 0 {
+			return notAligned(state)
+		}
+		return Seek(state.Index + n).Run(state)
+	}))
+}
+
+// Seek moves to the given offset from the start of the input, and the start of that byte.
+// The offset must not be past the end of the input.
+// result is nil
+func Seek(offset int64) parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		if offset < 0 {
+			return state.WithError(fmt.Errorf("can not seek to %v", offset))
+		}
+		if state.Source == nil {
+			return state.WithError(parse.ErrNoSource)
+		}
+		if offset > 0 {
+			// make sure the byte before the offset exists
+			buff := make([]byte, 1)
+			if n, _ := state.Source.ReadAt(buff, offset-1); n != 1 {
+				return state.WithError(fmt.Errorf("can not seek to %v past the end of input", offset))
+			}
+		}
+		next := state.WithResult(nil, offset)
+		next.Bit = 0
+		return next
+	})
+}
+
+// At runs the parser at the given offset from the start of the input, without
+// moving from the current index. Useful for formats with tables of offsets.
+// result is the result of parser
+func At(offset int64, parser parse.Parser) parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		at := state.WithResult(nil, offset)
+		at.Bit = 0
+		next := parser.Run(at)
+		if next.IsError {
+			return state.WithError(next.Err)
+		}
+		return state.WithResult(next.Result, state.Index)
+	})
+}
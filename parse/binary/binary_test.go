@@ -0,0 +1,89 @@
+package binary
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/gdey/ppc/parse"
+)
+
+func TestBinary(t *testing.T) {
+	tests := map[string]struct {
+		parser parse.Parser
+		input  string
+		result interface{}
+		index  int64
+		err    bool
+	}{
+		"uint8":                  {parser: Uint8(), input: "\xff", result: uint8(0xff), index: 1},
+		"int8":                   {parser: Int8(), input: "\xff", result: int8(-1), index: 1},
+		"uint16 big endian":      {parser: Uint16BE(), input: "\x01\x02", result: uint16(0x0102), index: 2},
+		"uint16 little endian":   {parser: Uint16LE(), input: "\x01\x02", result: uint16(0x0201), index: 2},
+		"uint32 big endian":      {parser: Uint32BE(), input: "\x01\x02\x03\x04", result: uint32(0x01020304), index: 4},
+		"uint32 little endian":   {parser: Uint32LE(), input: "\x01\x02\x03\x04", result: uint32(0x04030201), index: 4},
+		"uint64 big endian":      {parser: Uint64BE(), input: "\x01\x02\x03\x04\x05\x06\x07\x08", result: uint64(0x0102030405060708), index: 8},
+		"uint64 little endian":   {parser: Uint64LE(), input: "\x01\x02\x03\x04\x05\x06\x07\x08", result: uint64(0x0807060504030201), index: 8},
+		"int16":                  {parser: Int16(binary.BigEndian), input: "\xff\xfe", result: int16(-2), index: 2},
+		"int32":                  {parser: Int32(binary.LittleEndian), input: "\xfe\xff\xff\xff", result: int32(-2), index: 4},
+		"int64":                  {parser: Int64(binary.BigEndian), input: "\xff\xff\xff\xff\xff\xff\xff\xfe", result: int64(-2), index: 8},
+		"past the end":           {parser: Uint32BE(), input: "\x01\x02\x03", err: true},
+		"bytes":                  {parser: Bytes(2), input: "abc", result: []byte("ab"), index: 2},
+		"bytes past the end":     {parser: Bytes(4), input: "abc", err: true},
+		"negative bytes":         {parser: Bytes(-1), input: "abc", err: true},
+		"uvarint":                {parser: Uvarint(), input: "\xac\x02", result: uint64(300), index: 2},
+		"varint":                 {parser: Varint(), input: "\x03", result: int64(-2), index: 1},
+		"uvarint truncated":      {parser: Uvarint(), input: "\xac", err: true},
+		"varint truncated":       {parser: Varint(), input: "\x80\x80", err: true},
+		"uvarint overlong":       {parser: Uvarint(), input: "\x80\x80\x80\x80\x80\x80\x80\x80\x80\x80\x01", err: true},
+		"uvarint overflow":       {parser: Uvarint(), input: "\xff\xff\xff\xff\xff\xff\xff\xff\xff\x02", err: true},
+		"magic":                  {parser: Magic([]byte("\x89PNG")), input: "\x89PNG\r\n", result: []byte("\x89PNG"), index: 4},
+		"wrong magic":            {parser: Magic([]byte("\x89PNG")), input: "\x89PNx", err: true},
+		"short magic":            {parser: Magic([]byte("\x89PNG")), input: "\x89P", err: true},
+		"length prefixed bytes":  {parser: LengthPrefixed(Uint8(), nil), input: "\x02abc", result: []byte("ab"), index: 3},
+		"length past the end":    {parser: LengthPrefixed(Uint8(), nil), input: "\x05abc", err: true},
+		"length prefixed body":   {parser: LengthPrefixed(Uint8(), Uint16BE()), input: "\x02\x01\x02\x03", result: uint16(0x0102), index: 3},
+		"body reads past length": {parser: LengthPrefixed(Uint8(), Uint16BE()), input: "\x01\x01\x02", err: true},
+		"body leaves data":       {parser: LengthPrefixed(Uint8(), Uint8()), input: "\x02\x01\x02", err: true},
+		"count prefixed":         {parser: CountPrefixed(Uint8(), Uint8()), input: "\x02\x0a\x0b\x0c", result: []interface{}{uint8(10), uint8(11)}, index: 3},
+		"count past the end":     {parser: CountPrefixed(Uint8(), Uint8()), input: "\x03\x0a\x0b", err: true},
+		"count zero":             {parser: CountPrefixed(Uint8(), Uint8()), input: "\x00\x0a", result: []interface{}(nil), index: 1},
+		"skip":                   {parser: parse.SequenceOfNoNil(Skip(2), Uint8()), input: "abc", result: []interface{}{uint8('c')}, index: 3},
+		"skip past the end":      {parser: Skip(4), input: "abc", err: true},
+		"skip to the end":        {parser: Skip(3), input: "abc", result: nil, index: 3},
+		"align":                  {parser: parse.SequenceOfNoNil(Uint8(), Align(4), Uint8()), input: "abcde", result: []interface{}{uint8('a'), uint8('e')}, index: 5},
+		"align at the end":       {parser: parse.SequenceOfNoNil(Bytes(4), Align(4)), input: "abcd", result: []interface{}{[]byte("abcd")}, index: 4},
+		"align past the end":     {parser: parse.SequenceOf(Bytes(5), Align(4)), input: "abcdef", err: true},
+		"seek":                   {parser: parse.SequenceOfNoNil(Seek(3), Uint8()), input: "abcd", result: []interface{}{uint8('d')}, index: 4},
+		"seek back":              {parser: parse.SequenceOfNoNil(Skip(3), Seek(1), Uint8()), input: "abcd", result: []interface{}{uint8('b')}, index: 2},
+		"seek to the end":        {parser: Seek(4), input: "abcd", result: nil, index: 4},
+		"seek past the end":      {parser: Seek(5), input: "abcd", err: true},
+		"seek negative":          {parser: Seek(-1), input: "abcd", err: true},
+		"at":                     {parser: parse.SequenceOf(Uint8(), At(3, Uint8()), Uint8()), input: "abcd", result: []interface{}{uint8('a'), uint8('d'), uint8('b')}, index: 2},
+		"at past the end":        {parser: At(4, Uint8()), input: "abcd", err: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.Bytes(tc.parser, []byte(tc.input))
+			if tc.err {
+				if !state.IsError {
+					t.Fatalf("expected error, got %#v at %v", state.Result, state.Index)
+				}
+				return
+			}
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			if !reflect.DeepEqual(state.Result, tc.result) || state.Index != tc.index {
+				t.Errorf("expected %#v at %v got %#v at %v", tc.result, tc.index, state.Result, state.Index)
+			}
+		})
+	}
+}
+
+func TestSeekStream(t *testing.T) {
+	state := parse.State{Stream: parse.Slice{"a", "b"}}
+	if next := Seek(1).Run(state); next.Err != parse.ErrNoSource {
+		t.Errorf("expected ErrNoSource got %v", next.Err)
+	}
+}
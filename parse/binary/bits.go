@@ -0,0 +1,79 @@
+package binary
+
+import (
+	"fmt"
+
+	"github.com/gdey/ppc/parse"
+)
+
+// The bit parsers keep track of the bit within the current byte in parse.State.Bit.
+// The byte parsers (Bytes, Uint16BE, Uvarint, ...) need the state to be on a byte boundary;
+// use ByteAlign to skip the rest of a partially read byte.
+//
+//	header := parse.SequenceOf(
+//		binary.Bits(4), // version
+//		binary.Bits(4), // header length
+//		binary.Flag(),
+//		binary.Bits(7),
+//		binary.Uint16BE(),
+//	)
+
+// notAligned is the error for byte parsers run in the middle of a byte
+func notAligned(state parse.State) parse.State {
+	return state.WithError(fmt.Errorf("not byte aligned at %v bit %v", state.Index, state.Bit))
+}
+
+func readBits(n int, lsbFirst bool) parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		if n < 0 || n > 64 {
+			return state.WithError(fmt.Errorf("can not read %v bits", n))
+		}
+		bit := int(state.Bit)
+		count := (bit + n + 7) / 8
+		buff, nn, err := state.ReadNextBytes(count)
+		if nn != count {
+			return state.WithError(fmt.Errorf("expected %v bits at %v bit %v: %v", n, state.Index, bit, err))
+		}
+		var v uint64
+		for i := 0; i < n; i, bit = i+1, bit+1 {
+			b := buff[bit/8]
+			if lsbFirst {
+				v |= uint64(b>>uint(bit%8)&1) << uint(i)
+				continue
+			}
+			v = v<<1 | uint64(b>>uint(7-bit%8)&1)
+		}
+		next := state.WithResult(v, state.Index+int64(bit/8))
+		next.Bit = uint8(bit % 8)
+		return next
+	})
+}
+
+// Bits reads the next n bits, (0 to 64) taking the most significant bit of each byte first,
+// as used by most network protocol headers.
+// result is uint64
+func Bits(n int) parse.Parser { return readBits(n, false) }
+
+// BitsLSB reads the next n bits, (0 to 64) taking the least significant bit of each byte first,
+// the first bit read is the least significant bit of the result; as used by DEFLATE.
+// result is uint64
+func BitsLSB(n int) parse.Parser { return readBits(n, true) }
+
+// Flag reads the next bit, most significant bit first
+// result is bool
+func Flag() parse.Parser {
+	return parse.Map(Bits(1), func(r interface{}) interface{} { return r.(uint64) == 1 })
+}
+
+// ByteAlign skips the rest of the current byte, if it was partially read
+// result is nil
+func ByteAlign() parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		if state.Bit == 0 {
+			return state.WithResult(nil, state.Index)
+		}
+		next := state.WithResult(nil, state.Index+1)
+		next.Bit = 0
+		return next
+	})
+}
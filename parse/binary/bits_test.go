@@ -0,0 +1,101 @@
+package binary
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/gdey/ppc/parse"
+	"github.com/gdey/ppc/parse/match"
+)
+
+func TestBits(t *testing.T) {
+	tests := map[string]struct {
+		parser parse.Parser
+		input  string
+		result interface{}
+		index  int64
+		bit    uint8
+	}{
+		"msb first":          {parser: Bits(4), input: "\x1f", result: uint64(0x1), index: 0, bit: 4},
+		"lsb first":          {parser: BitsLSB(4), input: "\x1f", result: uint64(0xf), index: 0, bit: 4},
+		"msb across bytes":   {parser: parse.SequenceOf(Bits(3), Bits(7)), input: "\xb3\x5c", result: []interface{}{uint64(0x5), uint64(0x4d)}, index: 1, bit: 2},
+		"lsb across bytes":   {parser: parse.SequenceOf(BitsLSB(3), BitsLSB(7)), input: "\xb3\x5c", result: []interface{}{uint64(0x3), uint64(0x16)}, index: 1, bit: 2},
+		"whole bytes":        {parser: Bits(12), input: "\xab\xcd", result: uint64(0xabc), index: 1, bit: 4},
+		"zero bits":          {parser: Bits(0), input: "", result: uint64(0), index: 0, bit: 0},
+		"flag":               {parser: parse.SequenceOf(Flag(), Flag()), input: "\x80", result: []interface{}{true, false}, index: 0, bit: 2},
+		"many to the end":    {parser: parse.Many(Bits(1)), input: "\xa5", result: []interface{}{uint64(1), uint64(0), uint64(1), uint64(0), uint64(0), uint64(1), uint64(0), uint64(1)}, index: 1, bit: 0},
+		"byte align":         {parser: parse.SequenceOfNoNil(Bits(2), ByteAlign(), Uint8()), input: "\xff\x07", result: []interface{}{uint64(3), uint8(7)}, index: 2, bit: 0},
+		"byte align aligned": {parser: parse.SequenceOfNoNil(ByteAlign(), Uint8()), input: "\x07", result: []interface{}{uint8(7)}, index: 1, bit: 0},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.Bytes(tc.parser, []byte(tc.input))
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			if !reflect.DeepEqual(state.Result, tc.result) {
+				t.Errorf("expected %#v got %#v", tc.result, state.Result)
+			}
+			if state.Index != tc.index || state.Bit != tc.bit {
+				t.Errorf("expected index %v bit %v got %v bit %v", tc.index, tc.bit, state.Index, state.Bit)
+			}
+		})
+	}
+}
+
+func TestBitsErrors(t *testing.T) {
+	state := parse.Bytes(parse.SequenceOf(Bits(1), Uint8()), []byte("\xff\xff"))
+	if !state.IsError || state.Err.Error() != "not byte aligned at 0 bit 1" {
+		t.Errorf("expected not byte aligned at 0 bit 1, got %v", state.Err)
+	}
+	if state := parse.Bytes(Bits(9), []byte("\xff")); !state.IsError {
+		t.Errorf("expected reading past the end to fail, got %v", state.Result)
+	}
+	if state := parse.Bytes(Bits(65), []byte("\xff\xff\xff\xff\xff\xff\xff\xff\xff")); !state.IsError {
+		t.Errorf("expected reading 65 bits to fail, got %v", state.Result)
+	}
+}
+
+func TestBitsBacktrack(t *testing.T) {
+	// Uint8 fails in the middle of a byte, Optional must go back to the same bit
+	state := parse.Bytes(parse.SequenceOf(Bits(3), parse.Optional(Uint8()), Bits(5)), []byte("\xff"))
+	if state.IsError {
+		t.Fatalf("unexpected error: %v", state.Err)
+	}
+	results := state.Result.([]interface{})
+	if results[0] != uint64(7) || results[2] != uint64(31) {
+		t.Errorf("expected 7 and 31 got %v and %v", results[0], results[2])
+	}
+	if state.Index != 1 || state.Bit != 0 {
+		t.Errorf("expected index 1 bit 0 got %v bit %v", state.Index, state.Bit)
+	}
+}
+
+func TestBitsLookahead(t *testing.T) {
+	tests := map[string]struct {
+		parser parse.Parser
+		bit    uint8
+		err    bool
+	}{
+		"and matches":    {parser: parse.And(Bits(2))},
+		"and fails":      {parser: parse.And(Bits(6)), err: true},
+		"not matches":    {parser: parse.Not(Bits(6))},
+		"not fails":      {parser: parse.Not(Bits(2)), err: true},
+		"except matches": {parser: match.Except(Bits(2), Bits(6)), bit: 2},
+		"except fails":   {parser: match.Except(Bits(2), Bits(1)), err: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// start 3 bits into the only byte, leaving 5 bits
+			start := parse.State{Source: bytes.NewReader([]byte("\xff")), Bit: 3}
+			state := tc.parser.Run(start)
+			if state.IsError != tc.err {
+				t.Fatalf("error, expected %v got %v", tc.err, state.Err)
+			}
+			if bit := start.Bit + tc.bit; state.Index != 0 || state.Bit != bit {
+				t.Errorf("expected index 0 bit %v got %v bit %v", bit, state.Index, state.Bit)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package parse
+
+import (
+	"fmt"
+)
+
+// Tag is the result of the Tagged parser
+type Tag struct {
+	Name   string
+	Result interface{}
+	// Start and End are the indexes before and after the match
+	Start int64
+	End   int64
+}
+
+// Tagged will wrap the result of the parser in a Tag with the given name
+// result is Tag
+func Tagged(parser Parser, name string) Parser {
+	return Func(func(state State) State {
+		nextState := parser.Run(state)
+		if nextState.IsError {
+			return nextState
+		}
+		return nextState.WithResult(
+			Tag{
+				Name:   name,
+				Result: nextState.Result,
+				Start:  state.Index,
+				End:    nextState.Index,
+			},
+			nextState.Index,
+		)
+	})
+}
+
+// Spanned is the result of the Span parser
+type Spanned struct {
+	Result interface{}
+	Start  int64
+	End    int64
+}
+
+// Span will wrap the result of the parser with the start and end indexes of the match
+// result is Spanned
+func Span(parser Parser) Parser {
+	return Func(func(state State) State {
+		nextState := parser.Run(state)
+		if nextState.IsError {
+			return nextState
+		}
+		return nextState.WithResult(
+			Spanned{
+				Result: nextState.Result,
+				Start:  state.Index,
+				End:    nextState.Index,
+			},
+			nextState.Index,
+		)
+	})
+}
+
+// Text will replace the result of the parser with the source text that was consumed
+// result is a string
+func Text(parser Parser) Parser {
+	return Func(func(state State) State {
+		nextState := parser.Run(state)
+		if nextState.IsError {
+			return nextState
+		}
+		if nextState.Index <= state.Index {
+			return nextState.WithResult("", nextState.Index)
+		}
+		if state.Source == nil {
+			return state.WithError(ErrNoSource)
+		}
+		buff := make([]byte, nextState.Index-state.Index)
+		n, err := state.Source.ReadAt(buff, state.Index)
+		if n != len(buff) {
+			return state.WithError(fmt.Errorf("unable to read matched text at %v: %v", state.Index, err))
+		}
+		return nextState.WithResult(string(buff), nextState.Index)
+	})
+}
+
+// Captures will collect the results of all the Tagged parsers in the result of parser
+// into a map keyed by the tag name. As a tag may match more then once, (e.g. in Many)
+// the results are kept in the order they were matched.
+// Tags are looked for in []interface{} and nested Tag and Spanned results.
+// result is map[string][]interface{}
+func Captures(parser Parser) Parser {
+	return Map(parser, func(r interface{}) interface{} {
+		captures := make(map[string][]interface{})
+		collectTags(captures, r)
+		return captures
+	})
+}
+
+func collectTags(captures map[string][]interface{}, r interface{}) {
+	switch result := r.(type) {
+	case Tag:
+		captures[result.Name] = append(captures[result.Name], result.Result)
+		collectTags(captures, result.Result)
+	case Spanned:
+		collectTags(captures, result.Result)
+	case []interface{}:
+		for i := range result {
+			collectTags(captures, result[i])
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package parse
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// literal matches the string s
+func literal(s string) Parser {
+	return Func(func(state State) State {
+		buff, n, _ := state.ReadNextBytes(len(s))
+		if n != len(s) || string(buff) != s {
+			return state.WithError(fmt.Errorf("expected %q at %v", s, state.Index))
+		}
+		return state.WithResult(s, state.Index+int64(n))
+	})
+}
+
+// anyRune matches any one rune
+func anyRune() Parser {
+	return Func(func(state State) State {
+		r, n, err := state.ReadNextRune()
+		if err != nil {
+			return state.WithError(err)
+		}
+		return state.WithResult(r, state.Index+int64(n))
+	})
+}
+
+func TestTagged(t *testing.T) {
+	state := String(SequenceOf(literal("a"), Tagged(literal("bc"), "b")), "abcd")
+	if state.IsError {
+		t.Fatalf("unexpected error: %v", state.Err)
+	}
+	expected := Tag{Name: "b", Result: "bc", Start: 1, End: 3}
+	if tag := state.Result.([]interface{})[1]; tag != expected {
+		t.Errorf("expected %#v got %#v", expected, tag)
+	}
+	if state := String(Tagged(literal("x"), "x"), "y"); !state.IsError {
+		t.Errorf("expected error, got %#v", state.Result)
+	}
+}
+
+func TestSpan(t *testing.T) {
+	state := String(SequenceOf(literal("é"), Span(literal("ab"))), "éab")
+	if state.IsError {
+		t.Fatalf("unexpected error: %v", state.Err)
+	}
+	expected := Spanned{Result: "ab", Start: 2, End: 4}
+	if span := state.Result.([]interface{})[1]; span != expected {
+		t.Errorf("expected %#v got %#v", expected, span)
+	}
+	if state := String(Span(literal("x")), "y"); !state.IsError {
+		t.Errorf("expected error, got %#v", state.Result)
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := map[string]struct {
+		parser Parser
+		input  string
+		text   string
+		index  int64
+	}{
+		"ascii":       {parser: Text(Many(anyRune())), input: "abc", text: "abc", index: 3},
+		"multi byte":  {parser: Text(Many(anyRune())), input: "h\u00e9llo \U0001F600", text: "h\u00e9llo \U0001F600", index: 11},
+		"part":        {parser: Text(SequenceOf(anyRune(), anyRune())), input: "\u00e9\u00e8\u00ea", text: "\u00e9\u00e8", index: 4},
+		"empty match": {parser: Text(Many(literal("x"))), input: "abc", text: "", index: 0},
+		"after index": {parser: SequenceOf(literal("\u00e9"), Text(anyRune())), input: "\u00e9\u00e8", text: "\u00e8", index: 4},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := String(tc.parser, tc.input)
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			text := state.Result
+			if results, ok := text.([]interface{}); ok {
+				text = results[len(results)-1]
+			}
+			if text != tc.text || state.Index != tc.index {
+				t.Errorf("expected %q at %v got %q at %v", tc.text, tc.index, text, state.Index)
+			}
+		})
+	}
+}
+
+func TestCaptures(t *testing.T) {
+	pair := SequenceOf(Tagged(literal("k"), "key"), literal("="), Tagged(literal("v"), "value"), literal(";"))
+	parser := Captures(SequenceOf(
+		Tagged(literal("{"), "open"),
+		Many(pair),
+		Span(Tagged(Tagged(literal("}"), "inner"), "close")),
+	))
+	state := String(parser, "{k=v;k=v;}")
+	if state.IsError {
+		t.Fatalf("unexpected error: %v", state.Err)
+	}
+	expected := map[string][]interface{}{
+		"open":  {"{"},
+		"key":   {"k", "k"},
+		"value": {"v", "v"},
+		"close": {Tag{Name: "inner", Result: "}", Start: 9, End: 10}},
+		"inner": {"}"},
+	}
+	if !reflect.DeepEqual(state.Result, expected) {
+		t.Errorf("expected %v got %v", expected, state.Result)
+	}
+
+	// without any tags the map is empty
+	state = String(Captures(literal("x")), "x")
+	if captures, ok := state.Result.(map[string][]interface{}); !ok || len(captures) != 0 {
+		t.Errorf("expected no captures got %#v", state.Result)
+	}
+}
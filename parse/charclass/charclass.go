@@ -0,0 +1,220 @@
+/*
+Package charclass describes sets of runes, using a syntax like the one used by regular expressions.
+
+	charclass.MustParse(`[a-zA-Z0-9._-]`)
+	charclass.MustParse(`[^\s\[\\]`)
+	charclass.MustParse(`[\p{L}\p{Nd}_]`)
+
+A Class is compiled into a bitmap for ASCII and a sorted table of ranges for everything else,
+and its Contains method can be given to match.Rune, match.Runes, or match.RunesMinMax:
+
+	match.Runes(charclass.MustParse(`[a-z_]`).Contains, errors.New("expected identifier"))
+*/
+package charclass
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Range is an inclusive range of runes
+type Range struct {
+	Lo rune
+	Hi rune
+}
+
+// Class is a set of runes
+// The zero value is the empty set
+type Class struct {
+	ascii  [2]uint64
+	ranges []Range // sorted, non-overlapping, non-adjacent
+}
+
+// New returns a class for the given ranges
+func New(ranges ...Range) *Class {
+	rs := make([]Range, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Lo > r.Hi {
+			r.Lo, r.Hi = r.Hi, r.Lo
+		}
+		rs = append(rs, r)
+	}
+	return compile(rs)
+}
+
+// Of returns a class containing each of the runes in s
+func Of(s string) *Class {
+	var rs []Range
+	for _, r := range s {
+		rs = append(rs, Range{r, r})
+	}
+	return compile(rs)
+}
+
+// Between returns a class of the runes from lo to hi inclusive
+func Between(lo, hi rune) *Class { return New(Range{lo, hi}) }
+
+// Table returns a class for the runes in the given unicode table. (e.g. unicode.Letter)
+func Table(tables ...*unicode.RangeTable) *Class {
+	var rs []Range
+	for _, table := range tables {
+		for _, r16 := range table.R16 {
+			rs = appendStride(rs, rune(r16.Lo), rune(r16.Hi), rune(r16.Stride))
+		}
+		for _, r32 := range table.R32 {
+			rs = appendStride(rs, rune(r32.Lo), rune(r32.Hi), rune(r32.Stride))
+		}
+	}
+	return compile(rs)
+}
+
+func appendStride(rs []Range, lo, hi, stride rune) []Range {
+	if stride == 1 {
+		return append(rs, Range{lo, hi})
+	}
+	for r := lo; r <= hi; r += stride {
+		rs = append(rs, Range{r, r})
+	}
+	return rs
+}
+
+// Func returns a class of every rune for which fn returns true.
+// fn is called for every valid rune, so this is best done once at init time.
+func Func(fn func(rune) bool) *Class {
+	var rs []Range
+	start := rune(-1)
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		in := fn(r)
+		switch {
+		case in && start < 0:
+			start = r
+		case !in && start >= 0:
+			rs = append(rs, Range{start, r - 1})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		rs = append(rs, Range{start, unicode.MaxRune})
+	}
+	return compile(rs)
+}
+
+// compile normalizes the ranges, and builds the ascii bitmap
+func compile(rs []Range) *Class {
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Lo < rs[j].Lo })
+	merged := make([]Range, 0, len(rs))
+	for _, r := range rs {
+		last := len(merged) - 1
+		if last >= 0 && r.Lo <= merged[last].Hi+1 {
+			if r.Hi > merged[last].Hi {
+				merged[last].Hi = r.Hi
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	c := Class{ranges: merged}
+	for _, r := range merged {
+		if r.Lo > unicode.MaxASCII {
+			break
+		}
+		hi := r.Hi
+		if hi > unicode.MaxASCII {
+			hi = unicode.MaxASCII
+		}
+		for b := r.Lo; b <= hi; b++ {
+			c.ascii[b/64] |= 1 << uint(b%64)
+		}
+	}
+	return &c
+}
+
+// Contains reports whether r is in the class
+func (c *Class) Contains(r rune) bool {
+	if r < 0 {
+		return false
+	}
+	if r <= unicode.MaxASCII {
+		return c.ascii[r/64]&(1<<uint(r%64)) != 0
+	}
+	i := sort.Search(len(c.ranges), func(i int) bool { return c.ranges[i].Hi >= r })
+	return i < len(c.ranges) && c.ranges[i].Lo <= r
+}
+
+// Ranges returns the sorted, non-overlapping ranges that make up the class
+func (c *Class) Ranges() []Range {
+	rs := make([]Range, len(c.ranges))
+	copy(rs, c.ranges)
+	return rs
+}
+
+// IsEmpty reports whether there are no runes in the class
+func (c *Class) IsEmpty() bool { return len(c.ranges) == 0 }
+
+// Not returns the class of all runes not in c
+func (c *Class) Not() *Class {
+	var (
+		rs   []Range
+		next rune
+	)
+	for _, r := range c.ranges {
+		if r.Lo > next {
+			rs = append(rs, Range{next, r.Lo - 1})
+		}
+		next = r.Hi + 1
+	}
+	if next <= unicode.MaxRune {
+		rs = append(rs, Range{next, unicode.MaxRune})
+	}
+	return compile(rs)
+}
+
+// Union returns the class of runes in any of the given classes
+func Union(classes ...*Class) *Class {
+	var rs []Range
+	for _, c := range classes {
+		rs = append(rs, c.ranges...)
+	}
+	return compile(rs)
+}
+
+// Intersect returns the class of runes that are in all of the given classes
+// The intersection of no classes is the empty set
+func Intersect(classes ...*Class) *Class {
+	if len(classes) == 0 {
+		return &Class{}
+	}
+	rs := classes[0].ranges
+	for _, c := range classes[1:] {
+		rs = intersect(rs, c.ranges)
+	}
+	return compile(append([]Range(nil), rs...))
+}
+
+func intersect(a, b []Range) []Range {
+	var rs []Range
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		lo, hi := a[i].Lo, a[i].Hi
+		if b[j].Lo > lo {
+			lo = b[j].Lo
+		}
+		if b[j].Hi < hi {
+			hi = b[j].Hi
+		}
+		if lo <= hi {
+			rs = append(rs, Range{lo, hi})
+		}
+		if a[i].Hi < b[j].Hi {
+			i++
+		} else {
+			j++
+		}
+	}
+	return rs
+}
+
+// Minus returns the class of runes in c that are not in any of the others
+func (c *Class) Minus(others ...*Class) *Class {
+	return Intersect(c, Union(others...).Not())
+}
@@ -0,0 +1,183 @@
+package charclass
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"unicode"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		spec string
+		in   string
+		out  string
+	}{
+		"literals":           {spec: `[abc]`, in: "abc", out: "dA-"},
+		"negation":           {spec: `[^abc]`, in: "dA-é", out: "abc"},
+		"range":              {spec: `[a-cx-z]`, in: "abcxyz", out: "dw"},
+		"dash at the start":  {spec: `[-a]`, in: "-a", out: "b"},
+		"dash at the end":    {spec: `[a-]`, in: "-a", out: "b"},
+		"negated dash":       {spec: `[^-]`, in: "a", out: "-"},
+		"bracket first":      {spec: `[]a]`, in: "]a", out: "["},
+		"negated bracket":    {spec: `[^]]`, in: "a[", out: "]"},
+		"escaped brackets":   {spec: `[\[\]\\\-\^]`, in: "[]\\-^", out: "a"},
+		"escaped range":      {spec: `[\--\/]`, in: "-./", out: ",0"},
+		"control escapes":    {spec: `[\n\t\r\f]`, in: "\n\t\r\f", out: " "},
+		"hex":                {spec: `[\x41\x{1F600}]`, in: "A\U0001F600", out: "B\U0001F601"},
+		"hex range":          {spec: `[\x{100}-\x{10F}]`, in: "Āď", out: "ÿĐ"},
+		"digit":              {spec: `\d`, in: "09", out: "a٣"},
+		"not digit":          {spec: `\D`, in: "a٣", out: "09"},
+		"space":              {spec: `\s`, in: " \t\n\f\r", out: "a "},
+		"not space":          {spec: `\S`, in: "a ", out: " \t"},
+		"word":               {spec: `\w`, in: "azAZ09_", out: "-é"},
+		"not word":           {spec: `\W`, in: "-é", out: "a_"},
+		"unicode name":       {spec: `\p{Greek}`, in: "αΩ", out: "a"},
+		"unicode short name": {spec: `\pL`, in: "aéα", out: "1-"},
+		"not unicode":        {spec: `\P{L}`, in: "1-", out: "aé"},
+		"any":                {spec: `[\p{Any}]`, in: "a\x00\U0010FFFF", out: ""},
+		"classes in bracket": {spec: `[\d\p{Greek}_]`, in: "1α_", out: "a"},
+		"negated classes":    {spec: `[^\s\d]`, in: "a", out: " 1"},
+		"single escape":      {spec: `\.`, in: ".", out: "a"},
+		"replacement rune":   {spec: "[�]", in: "�", out: "a"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := Parse(tc.spec)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, r := range tc.in {
+				if !c.Contains(r) {
+					t.Errorf("expected %q to contain %q", tc.spec, r)
+				}
+			}
+			for _, r := range tc.out {
+				if c.Contains(r) {
+					t.Errorf("expected %q to not contain %q", tc.spec, r)
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]struct {
+		spec   string
+		offset int
+		msg    string
+	}{
+		"empty":              {spec: ``, offset: 0, msg: "empty class"},
+		"no bracket":         {spec: `abc`, offset: 0, msg: `expected '[' or '\'`},
+		"unclosed":           {spec: `[abc`, offset: 4, msg: "missing closing ]"},
+		"empty brackets":     {spec: `[]`, offset: 2, msg: "missing closing ]"},
+		"after class":        {spec: `[a]b`, offset: 3, msg: `unexpected "b" after class`},
+		"reversed range":     {spec: `[z-a]`, offset: 3, msg: `invalid range 'z'-'a'`},
+		"class range end":    {spec: `[a-\d]`, offset: 3, msg: "invalid range end"},
+		"unknown escape":     {spec: `[a\q]`, offset: 3, msg: `unknown escape \q`},
+		"trailing slash":     {spec: `\`, offset: 1, msg: `trailing \`},
+		"unknown unicode":    {spec: `[\p{Nope}]`, offset: 3, msg: `unknown unicode class "Nope"`},
+		"unclosed unicode":   {spec: `\p{L`, offset: 2, msg: "missing closing }"},
+		"missing unicode":    {spec: `\p`, offset: 2, msg: "missing unicode class name"},
+		"short hex":          {spec: `[\x4]`, offset: 3, msg: `invalid hex code point "4]"`},
+		"hex at the end":     {spec: `\x4`, offset: 2, msg: "expected two hex digits"},
+		"hex too big":        {spec: `[\x{110000}]`, offset: 3, msg: `invalid hex code point "110000"`},
+		"hex too long":       {spec: `\x{0000041}`, offset: 2, msg: `invalid hex code point "0000041"`},
+		"empty hex":          {spec: `\x{}`, offset: 2, msg: `invalid hex code point ""`},
+		"unclosed hex":       {spec: `\x{41`, offset: 2, msg: "missing closing }"},
+		"invalid utf8":       {spec: "[a\xff]", offset: 2, msg: "invalid utf8"},
+		"range invalid utf8": {spec: "[a-\xff]", offset: 3, msg: "invalid utf8"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(tc.spec)
+			var cerr Error
+			if !errors.As(err, &cerr) {
+				t.Fatalf("expected an Error got %v", err)
+			}
+			if cerr.Offset != tc.offset || cerr.Msg != tc.msg || cerr.Spec != tc.spec {
+				t.Errorf("expected %q at %v got %q at %v", tc.msg, tc.offset, cerr.Msg, cerr.Offset)
+			}
+		})
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	if c := MustParse(`[a-z]`); !c.Contains('q') {
+		t.Errorf("expected [a-z] to contain q")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected MustParse to panic")
+		}
+	}()
+	MustParse(`[a-z`)
+}
+
+func TestBitmapBoundary(t *testing.T) {
+	tests := map[string]struct {
+		class *Class
+		in    []rune
+		out   []rune
+	}{
+		"across":     {class: Between(0x7E, 0x81), in: []rune{0x7E, 0x7F, 0x80, 0x81}, out: []rune{0x7D, 0x82}},
+		"ascii only": {class: Between(0x70, 0x7F), in: []rune{0x70, 0x7F}, out: []rune{0x6F, 0x80}},
+		"above":      {class: Between(0x80, 0x90), in: []rune{0x80, 0x90}, out: []rune{0x7F, 0x91}},
+		"not ascii":  {class: Between(0, 0x7F).Not(), in: []rune{0x80, unicode.MaxRune}, out: []rune{0, 0x7F, -1}},
+		"word edges": {class: Of("\x00\x3f\x40\x7f"), in: []rune{0x00, 0x3F, 0x40, 0x7F}, out: []rune{0x01, 0x3E, 0x41, 0x7E}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, r := range tc.in {
+				if !tc.class.Contains(r) {
+					t.Errorf("expected %#x in the class", r)
+				}
+			}
+			for _, r := range tc.out {
+				if tc.class.Contains(r) {
+					t.Errorf("expected %#x not in the class", r)
+				}
+			}
+		})
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	az := Between('a', 'z')
+	vowels := Of("aeiou")
+	tests := map[string]struct {
+		class  *Class
+		ranges []Range
+	}{
+		"new merges":      {class: New(Range{'c', 'a'}, Range{'d', 'f'}, Range{'x', 'x'}), ranges: []Range{{'a', 'f'}, {'x', 'x'}}},
+		"not":             {class: Between('b', 'y').Not(), ranges: []Range{{0, 'a'}, {'z', unicode.MaxRune}}},
+		"not not":         {class: az.Not().Not(), ranges: []Range{{'a', 'z'}}},
+		"not empty":       {class: (&Class{}).Not(), ranges: []Range{{0, unicode.MaxRune}}},
+		"not everything":  {class: Between(0, unicode.MaxRune).Not(), ranges: []Range{}},
+		"union":           {class: Union(Between('a', 'c'), Between('b', 'e'), Of("z")), ranges: []Range{{'a', 'e'}, {'z', 'z'}}},
+		"union adjacent":  {class: Union(Between('a', 'c'), Between('d', 'e')), ranges: []Range{{'a', 'e'}}},
+		"intersect":       {class: Intersect(az, Between('x', 0x100), Of("xz!")), ranges: []Range{{'x', 'x'}, {'z', 'z'}}},
+		"intersect none":  {class: Intersect(), ranges: nil},
+		"intersect apart": {class: Intersect(Between('a', 'c'), Between('x', 'z')), ranges: []Range{}},
+		"minus":           {class: az.Minus(vowels, Between('w', 'z')), ranges: []Range{{'b', 'd'}, {'f', 'h'}, {'j', 'n'}, {'p', 't'}, {'v', 'v'}}},
+		"minus nothing":   {class: vowels.Minus(), ranges: []Range{{'a', 'a'}, {'e', 'e'}, {'i', 'i'}, {'o', 'o'}, {'u', 'u'}}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ranges := tc.class.Ranges()
+			if len(ranges) == 0 && len(tc.ranges) == 0 {
+				if !tc.class.IsEmpty() {
+					t.Errorf("expected the class to be empty")
+				}
+				return
+			}
+			if !reflect.DeepEqual(ranges, tc.ranges) {
+				t.Errorf("expected %q got %q", tc.ranges, ranges)
+			}
+		})
+	}
+	// the ascii bitmap is rebuilt for the results
+	if c := az.Minus(vowels); c.Contains('a') || !c.Contains('b') {
+		t.Errorf("expected b and not a in [a-z] minus vowels")
+	}
+}
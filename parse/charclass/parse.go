@@ -0,0 +1,302 @@
+package charclass
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Error is returned when a class description can not be parsed
+type Error struct {
+	Spec   string
+	Offset int
+	Msg    string
+}
+
+func (err Error) Error() string {
+	return fmt.Sprintf("charclass %q at %v: %v", err.Spec, err.Offset, err.Msg)
+}
+
+var (
+	digit = Between('0', '9')
+	space = Of("\t\n\f\r ")
+	word  = New(Range{'0', '9'}, Range{'A', 'Z'}, Range{'a', 'z'}, Range{'_', '_'})
+)
+
+// MustParse is like Parse but panics if the description can not be parsed
+func MustParse(spec string) *Class {
+	c, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Parse will compile the description of a class.
+// The description is either a single escape, or a bracket expression like in regular expressions:
+//
+//	[abc]       a, b, or c
+//	[^abc]      anything but a, b, or c
+//	[a-z0-9_-]  ranges, a '-' at the start or end is a literal
+//	\d \s \w    ASCII digits, ASCII whitespace, ASCII word characters ([0-9A-Za-z_])
+//	\D \S \W    the negation of the above
+//	\p{Greek}   unicode category, script, or property; \pL is short for \p{L}
+//	\P{Greek}   the negation of the above
+//	\n \t \r \f escaped control characters
+//	\x7F \x{10FFFF} hex code points
+//	\[ \] \\ \- \^ any other punctuation is escaped by \
+func Parse(spec string) (*Class, error) {
+	p := parser{spec: spec}
+	c, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type parser struct {
+	spec string
+	pos  int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return Error{Spec: p.spec, Offset: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.spec) }
+
+func (p *parser) peek() rune {
+	r, _ := utf8.DecodeRuneInString(p.spec[p.pos:])
+	return r
+}
+
+func (p *parser) next() rune {
+	r, n := utf8.DecodeRuneInString(p.spec[p.pos:])
+	p.pos += n
+	return r
+}
+
+func (p *parser) parse() (*Class, error) {
+	if p.eof() {
+		return nil, p.errorf("empty class")
+	}
+	var (
+		c   *Class
+		err error
+	)
+	switch p.peek() {
+	case '[':
+		c, err = p.parseBracket()
+	case '\\':
+		var lit rune
+		c, lit, err = p.parseEscape()
+		if err == nil && c == nil {
+			c = Of(string(lit))
+		}
+	default:
+		return nil, p.errorf("expected '[' or '\\'")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q after class", p.spec[p.pos:])
+	}
+	return c, nil
+}
+
+func (p *parser) parseBracket() (*Class, error) {
+	p.next() // [
+	negate := false
+	if !p.eof() && p.peek() == '^' {
+		p.next()
+		negate = true
+	}
+	var (
+		classes []*Class
+		ranges  []Range
+		first   = true
+	)
+	for {
+		if p.eof() {
+			return nil, p.errorf("missing closing ]")
+		}
+		r := p.peek()
+		if r == ']' && !first {
+			p.next()
+			break
+		}
+		first = false
+
+		lo, class, err := p.parseItem()
+		if err != nil {
+			return nil, err
+		}
+		if class != nil {
+			classes = append(classes, class)
+			continue
+		}
+		// a range?
+		if strings.HasPrefix(p.spec[p.pos:], "-") && !strings.HasPrefix(p.spec[p.pos:], "-]") {
+			p.next() // -
+			start := p.pos
+			hi, class, err := p.parseItem()
+			if err != nil {
+				return nil, err
+			}
+			if class != nil {
+				p.pos = start
+				return nil, p.errorf("invalid range end")
+			}
+			if hi < lo {
+				p.pos = start
+				return nil, p.errorf("invalid range %q-%q", lo, hi)
+			}
+			ranges = append(ranges, Range{lo, hi})
+			continue
+		}
+		ranges = append(ranges, Range{lo, lo})
+	}
+	c := Union(append(classes, New(ranges...))...)
+	if negate {
+		return c.Not(), nil
+	}
+	return c, nil
+}
+
+// parseItem parses a literal or an escape; if the escape is a class, class is not nil
+func (p *parser) parseItem() (lit rune, class *Class, err error) {
+	if p.peek() == '\\' {
+		class, lit, err = p.parseEscape()
+		return lit, class, err
+	}
+	if r, n := utf8.DecodeRuneInString(p.spec[p.pos:]); r == utf8.RuneError && n == 1 {
+		return 0, nil, p.errorf("invalid utf8")
+	}
+	return p.next(), nil, nil
+}
+
+func (p *parser) parseEscape() (class *Class, lit rune, err error) {
+	p.next() // \
+	if p.eof() {
+		return nil, 0, p.errorf("trailing \\")
+	}
+	r := p.next()
+	switch r {
+	case 'd':
+		return digit, 0, nil
+	case 'D':
+		return digit.Not(), 0, nil
+	case 's':
+		return space, 0, nil
+	case 'S':
+		return space.Not(), 0, nil
+	case 'w':
+		return word, 0, nil
+	case 'W':
+		return word.Not(), 0, nil
+	case 'n':
+		return nil, '\n', nil
+	case 't':
+		return nil, '\t', nil
+	case 'r':
+		return nil, '\r', nil
+	case 'f':
+		return nil, '\f', nil
+	case 'p', 'P':
+		c, err := p.parseUnicodeName()
+		if err != nil {
+			return nil, 0, err
+		}
+		if r == 'P' {
+			c = c.Not()
+		}
+		return c, 0, nil
+	case 'x':
+		lit, err := p.parseHex()
+		return nil, lit, err
+	}
+	if r < utf8.RuneSelf && !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+		p.pos--
+		return nil, 0, p.errorf("unknown escape \\%c", r)
+	}
+	return nil, r, nil
+}
+
+func (p *parser) parseUnicodeName() (*Class, error) {
+	if p.eof() {
+		return nil, p.errorf("missing unicode class name")
+	}
+	var name string
+	start := p.pos
+	if p.peek() == '{' {
+		end := strings.IndexByte(p.spec[p.pos:], '}')
+		if end < 0 {
+			return nil, p.errorf("missing closing }")
+		}
+		name = p.spec[p.pos+1 : p.pos+end]
+		p.pos += end + 1
+	} else {
+		name = string(p.next())
+	}
+	if name == "Any" {
+		return Between(0, unicode.MaxRune), nil
+	}
+	for _, tables := range []map[string]*unicode.RangeTable{
+		unicode.Categories,
+		unicode.Scripts,
+		unicode.Properties,
+	} {
+		if table, ok := tables[name]; ok {
+			return Table(table), nil
+		}
+	}
+	p.pos = start
+	return nil, p.errorf("unknown unicode class %q", name)
+}
+
+func (p *parser) parseHex() (rune, error) {
+	var digits string
+	start := p.pos
+	if !p.eof() && p.peek() == '{' {
+		end := strings.IndexByte(p.spec[p.pos:], '}')
+		if end < 0 {
+			return 0, p.errorf("missing closing }")
+		}
+		digits = p.spec[p.pos+1 : p.pos+end]
+		p.pos += end + 1
+	} else {
+		if len(p.spec)-p.pos < 2 {
+			return 0, p.errorf("expected two hex digits")
+		}
+		digits = p.spec[p.pos : p.pos+2]
+		p.pos += 2
+	}
+	// errors are reported at the start of the digits
+	end := p.pos
+	p.pos = start
+	var r rune
+	if len(digits) == 0 || len(digits) > 6 {
+		return 0, p.errorf("invalid hex code point %q", digits)
+	}
+	for _, d := range digits {
+		var v rune
+		switch {
+		case '0' <= d && d <= '9':
+			v = d - '0'
+		case 'a' <= d && d <= 'f':
+			v = d - 'a' + 10
+		case 'A' <= d && d <= 'F':
+			v = d - 'A' + 10
+		default:
+			return 0, p.errorf("invalid hex code point %q", digits)
+		}
+		r = r*16 + v
+	}
+	if r > unicode.MaxRune {
+		return 0, p.errorf("invalid hex code point %q", digits)
+	}
+	p.pos = end
+	return r, nil
+}
@@ -0,0 +1,299 @@
+/*
+Package grapheme splits text into grapheme clusters (what a reader would call a character) following
+the extended grapheme cluster rules of Unicode Standard Annex #29, and measures how many cells text
+takes up on a terminal, based on the East Asian Width property.
+
+The property tables are built from the unicode package where it has them, and approximated
+from the code point ranges where it does not (Extended_Pictographic, Prepend, and East Asian Width).
+*/
+package grapheme
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+type property uint8
+
+const (
+	propAny property = iota
+	propCR
+	propLF
+	propControl
+	propExtend
+	propZWJ
+	propRegionalIndicator
+	propPrepend
+	propSpacingMark
+	propL
+	propV
+	propT
+	propLV
+	propLVT
+)
+
+const zwj = 0x200D
+
+var (
+	extendTable = []*unicode.RangeTable{
+		unicode.Mn,
+		unicode.Me,
+		unicode.Other_Grapheme_Extend,
+	}
+	// emoji modifiers are Extend
+	emojiModifier = &unicode.RangeTable{
+		R32: []unicode.Range32{{Lo: 0x1F3FB, Hi: 0x1F3FF, Stride: 1}},
+	}
+	prependTable = &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: 0x0600, Hi: 0x0605, Stride: 1},
+			{Lo: 0x06DD, Hi: 0x06DD, Stride: 1},
+			{Lo: 0x070F, Hi: 0x070F, Stride: 1},
+			{Lo: 0x0890, Hi: 0x0891, Stride: 1},
+			{Lo: 0x08E2, Hi: 0x08E2, Stride: 1},
+			{Lo: 0x0D4E, Hi: 0x0D4E, Stride: 1},
+		},
+		R32: []unicode.Range32{
+			{Lo: 0x110BD, Hi: 0x110BD, Stride: 1},
+			{Lo: 0x110CD, Hi: 0x110CD, Stride: 1},
+			{Lo: 0x111C2, Hi: 0x111C3, Stride: 1},
+			{Lo: 0x11A3A, Hi: 0x11A3A, Stride: 1},
+			{Lo: 0x11A84, Hi: 0x11A89, Stride: 1},
+			{Lo: 0x11D46, Hi: 0x11D46, Stride: 1},
+		},
+	}
+	// extendedPictographic approximates the Extended_Pictographic property
+	extendedPictographic = &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: 0x00A9, Hi: 0x00A9, Stride: 1},
+			{Lo: 0x00AE, Hi: 0x00AE, Stride: 1},
+			{Lo: 0x203C, Hi: 0x203C, Stride: 1},
+			{Lo: 0x2049, Hi: 0x2049, Stride: 1},
+			{Lo: 0x2122, Hi: 0x2122, Stride: 1},
+			{Lo: 0x2139, Hi: 0x2139, Stride: 1},
+			{Lo: 0x2194, Hi: 0x2199, Stride: 1},
+			{Lo: 0x21A9, Hi: 0x21AA, Stride: 1},
+			{Lo: 0x231A, Hi: 0x231B, Stride: 1},
+			{Lo: 0x2328, Hi: 0x2328, Stride: 1},
+			{Lo: 0x2388, Hi: 0x2388, Stride: 1},
+			{Lo: 0x23CF, Hi: 0x23CF, Stride: 1},
+			{Lo: 0x23E9, Hi: 0x23F3, Stride: 1},
+			{Lo: 0x23F8, Hi: 0x23FA, Stride: 1},
+			{Lo: 0x24C2, Hi: 0x24C2, Stride: 1},
+			{Lo: 0x25AA, Hi: 0x25AB, Stride: 1},
+			{Lo: 0x25B6, Hi: 0x25B6, Stride: 1},
+			{Lo: 0x25C0, Hi: 0x25C0, Stride: 1},
+			{Lo: 0x25FB, Hi: 0x25FE, Stride: 1},
+			{Lo: 0x2600, Hi: 0x27BF, Stride: 1},
+			{Lo: 0x2934, Hi: 0x2935, Stride: 1},
+			{Lo: 0x2B05, Hi: 0x2B07, Stride: 1},
+			{Lo: 0x2B1B, Hi: 0x2B1C, Stride: 1},
+			{Lo: 0x2B50, Hi: 0x2B50, Stride: 1},
+			{Lo: 0x2B55, Hi: 0x2B55, Stride: 1},
+			{Lo: 0x3030, Hi: 0x3030, Stride: 1},
+			{Lo: 0x303D, Hi: 0x303D, Stride: 1},
+			{Lo: 0x3297, Hi: 0x3297, Stride: 1},
+			{Lo: 0x3299, Hi: 0x3299, Stride: 1},
+		},
+		R32: []unicode.Range32{
+			{Lo: 0x1F000, Hi: 0x1F0FF, Stride: 1},
+			{Lo: 0x1F10D, Hi: 0x1F10F, Stride: 1},
+			{Lo: 0x1F12F, Hi: 0x1F12F, Stride: 1},
+			{Lo: 0x1F16C, Hi: 0x1F171, Stride: 1},
+			{Lo: 0x1F17E, Hi: 0x1F17F, Stride: 1},
+			{Lo: 0x1F18E, Hi: 0x1F18E, Stride: 1},
+			{Lo: 0x1F191, Hi: 0x1F19A, Stride: 1},
+			{Lo: 0x1F1AD, Hi: 0x1F1E5, Stride: 1},
+			{Lo: 0x1F201, Hi: 0x1F20F, Stride: 1},
+			{Lo: 0x1F21A, Hi: 0x1F21A, Stride: 1},
+			{Lo: 0x1F22F, Hi: 0x1F22F, Stride: 1},
+			{Lo: 0x1F232, Hi: 0x1F23A, Stride: 1},
+			{Lo: 0x1F23C, Hi: 0x1F23F, Stride: 1},
+			{Lo: 0x1F249, Hi: 0x1F3FA, Stride: 1},
+			{Lo: 0x1F400, Hi: 0x1F53D, Stride: 1},
+			{Lo: 0x1F546, Hi: 0x1F64F, Stride: 1},
+			{Lo: 0x1F680, Hi: 0x1F6FF, Stride: 1},
+			{Lo: 0x1F774, Hi: 0x1F77F, Stride: 1},
+			{Lo: 0x1F7D5, Hi: 0x1F7FF, Stride: 1},
+			{Lo: 0x1F80C, Hi: 0x1F80F, Stride: 1},
+			{Lo: 0x1F848, Hi: 0x1F84F, Stride: 1},
+			{Lo: 0x1F85A, Hi: 0x1F85F, Stride: 1},
+			{Lo: 0x1F888, Hi: 0x1F88F, Stride: 1},
+			{Lo: 0x1F8AE, Hi: 0x1F8FF, Stride: 1},
+			{Lo: 0x1F90C, Hi: 0x1F93A, Stride: 1},
+			{Lo: 0x1F93C, Hi: 0x1F945, Stride: 1},
+			{Lo: 0x1F947, Hi: 0x1FAFF, Stride: 1},
+			{Lo: 0x1FC00, Hi: 0x1FFFD, Stride: 1},
+		},
+	}
+)
+
+func isExtendedPictographic(r rune) bool { return unicode.Is(extendedPictographic, r) }
+
+func propertyOf(r rune) property {
+	switch {
+	case r == '\r':
+		return propCR
+	case r == '\n':
+		return propLF
+	case r == zwj:
+		return propZWJ
+	case 0x1F1E6 <= r && r <= 0x1F1FF:
+		return propRegionalIndicator
+	case 0x1100 <= r && r <= 0x115F, 0xA960 <= r && r <= 0xA97C:
+		return propL
+	case 0x1160 <= r && r <= 0x11A7, 0xD7B0 <= r && r <= 0xD7C6:
+		return propV
+	case 0x11A8 <= r && r <= 0x11FF, 0xD7CB <= r && r <= 0xD7FB:
+		return propT
+	case 0xAC00 <= r && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return propLV
+		}
+		return propLVT
+	case unicode.In(r, extendTable...) || unicode.Is(emojiModifier, r) || r == 0x200C:
+		return propExtend
+	case unicode.Is(prependTable, r):
+		return propPrepend
+	case unicode.Is(unicode.Mc, r):
+		return propSpacingMark
+	case r == 0x0E33 || r == 0x0EB3:
+		// Thai and Lao SARA AM
+		return propSpacingMark
+	case unicode.In(r, unicode.Cc, unicode.Zl, unicode.Zp) ||
+		(unicode.Is(unicode.Cf, r) && r != 0x200C && r != zwj):
+		return propControl
+	}
+	return propAny
+}
+
+// Segmenter finds the boundaries of grapheme clusters as runes are fed to it one at a time.
+// The zero value is ready to use.
+type Segmenter struct {
+	started bool
+	prev    property
+	// riCount is the number of regional indicators in a row before the current rune
+	riCount int
+	// emoji is true if we are in a Extended_Pictographic Extend* sequence
+	emoji bool
+	// emojiZWJ is true if the previous rune was a ZWJ that followed an emoji sequence
+	emojiZWJ bool
+}
+
+// Break reports whether there is a cluster boundary before r, and then adds r to the segmenter.
+// The first rune given never has a boundary before it.
+func (s *Segmenter) Break(r rune) bool {
+	prop := propertyOf(r)
+	brk := s.isBreak(prop, r)
+
+	// update the state for the next rune
+	switch {
+	case prop == propRegionalIndicator:
+		if brk || s.prev != propRegionalIndicator {
+			s.riCount = 0
+		}
+		s.riCount++
+	default:
+		s.riCount = 0
+	}
+	s.emojiZWJ = prop == propZWJ && s.emoji
+	switch {
+	case isExtendedPictographic(r):
+		s.emoji = true
+	case prop == propExtend && s.emoji && !brk:
+		// stay in the emoji sequence
+	default:
+		s.emoji = false
+	}
+	s.prev = prop
+	s.started = true
+	return brk
+}
+
+// Reset will clear the segmenter, so the next rune is the start of a cluster
+func (s *Segmenter) Reset() { *s = Segmenter{} }
+
+func (s *Segmenter) isBreak(prop property, r rune) bool {
+	if !s.started {
+		return false
+	}
+	prev := s.prev
+	switch {
+	// GB3
+	case prev == propCR && prop == propLF:
+		return false
+	// GB4, GB5
+	case prev == propCR || prev == propLF || prev == propControl:
+		return true
+	case prop == propCR || prop == propLF || prop == propControl:
+		return true
+	// GB6
+	case prev == propL && (prop == propL || prop == propV || prop == propLV || prop == propLVT):
+		return false
+	// GB7
+	case (prev == propLV || prev == propV) && (prop == propV || prop == propT):
+		return false
+	// GB8
+	case (prev == propLVT || prev == propT) && prop == propT:
+		return false
+	// GB9, GB9a
+	case prop == propExtend || prop == propZWJ || prop == propSpacingMark:
+		return false
+	// GB9b
+	case prev == propPrepend:
+		return false
+	// GB11
+	case s.emojiZWJ && isExtendedPictographic(r):
+		return false
+	// GB12, GB13
+	case prev == propRegionalIndicator && prop == propRegionalIndicator:
+		return s.riCount%2 == 0
+	}
+	// GB999
+	return true
+}
+
+// FirstCluster returns the number of bytes in the first grapheme cluster of b
+func FirstCluster(b []byte) int {
+	var seg Segmenter
+	for i := 0; i < len(b); {
+		r, n := utf8.DecodeRune(b[i:])
+		if seg.Break(r) {
+			return i
+		}
+		i += n
+	}
+	return len(b)
+}
+
+// FirstClusterInString is like FirstCluster but for a string
+func FirstClusterInString(s string) int {
+	var seg Segmenter
+	for i, r := range s {
+		if seg.Break(r) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// Clusters splits s into grapheme clusters
+func Clusters(s string) []string {
+	var clusters []string
+	for len(s) > 0 {
+		n := FirstClusterInString(s)
+		clusters = append(clusters, s[:n])
+		s = s[n:]
+	}
+	return clusters
+}
+
+// Count returns the number of grapheme clusters in s
+func Count(s string) int {
+	count := 0
+	for len(s) > 0 {
+		s = s[FirstClusterInString(s):]
+		count++
+	}
+	return count
+}
@@ -0,0 +1,94 @@
+package grapheme
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClusters(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		clusters []string
+	}{
+		"ascii":              {input: "ab", clusters: []string{"a", "b"}},
+		"crlf":               {input: "a\r\nb", clusters: []string{"a", "\r\n", "b"}},
+		"lfcr":               {input: "\n\r", clusters: []string{"\n", "\r"}},
+		"combining marks":    {input: "e\u0301\u0323x", clusters: []string{"e\u0301\u0323", "x"}},
+		"flags":              {input: "\U0001F1FA\U0001F1F8\U0001F1EC\U0001F1E7", clusters: []string{"\U0001F1FA\U0001F1F8", "\U0001F1EC\U0001F1E7"}},
+		"odd flag":           {input: "\U0001F1FA\U0001F1F8\U0001F1EC", clusters: []string{"\U0001F1FA\U0001F1F8", "\U0001F1EC"}},
+		"zwj family":         {input: "\U0001F468\u200D\U0001F469\u200D\U0001F467!", clusters: []string{"\U0001F468\u200D\U0001F469\u200D\U0001F467", "!"}},
+		"zwj not emoji":      {input: "a\u200D\U0001F469", clusters: []string{"a\u200D", "\U0001F469"}},
+		"skin tone":          {input: "\U0001F44D\U0001F3FD\U0001F44D", clusters: []string{"\U0001F44D\U0001F3FD", "\U0001F44D"}},
+		"skin tone zwj":      {input: "\U0001F469\U0001F3FD\u200D\U0001F4BB", clusters: []string{"\U0001F469\U0001F3FD\u200D\U0001F4BB"}},
+		"hangul syllables":   {input: "한글", clusters: []string{"한", "글"}},
+		"hangul jamo":        {input: "\u1112\u1161\u11AB\u1100", clusters: []string{"\u1112\u1161\u11AB", "\u1100"}},
+		"hangul lv and t":    {input: "하\u11AB", clusters: []string{"하\u11AB"}},
+		"prepend":            {input: "\u0600a b", clusters: []string{"\u0600a", " ", "b"}},
+		"spacing mark":       {input: "क\u093F", clusters: []string{"क\u093F"}},
+		"emoji presentation": {input: "❤\uFE0Fx", clusters: []string{"❤\uFE0F", "x"}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := Clusters(tc.input)
+			if !reflect.DeepEqual(got, tc.clusters) {
+				t.Errorf("clusters, expected %q got %q", tc.clusters, got)
+			}
+			if count := Count(tc.input); count != len(tc.clusters) {
+				t.Errorf("count, expected %v got %v", len(tc.clusters), count)
+			}
+			if n := FirstCluster([]byte(tc.input)); n != len(tc.clusters[0]) {
+				t.Errorf("first cluster, expected %v got %v", len(tc.clusters[0]), n)
+			}
+		})
+	}
+}
+
+func TestWidth(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		width int
+	}{
+		"ascii":              {input: "abc", width: 3},
+		"combining marks":    {input: "e\u0301", width: 1},
+		"wide":               {input: "世界", width: 4},
+		"hangul syllable":    {input: "한", width: 2},
+		"hangul jamo":        {input: "\u1112\u1161\u11AB", width: 2},
+		"flag":               {input: "\U0001F1FA\U0001F1F8", width: 2},
+		"zwj family":         {input: "\U0001F468\u200D\U0001F469\u200D\U0001F467", width: 2},
+		"skin tone":          {input: "\U0001F44D\U0001F3FD", width: 2},
+		"emoji presentation": {input: "❤\uFE0F", width: 2},
+		"prepend":            {input: "\u0600a", width: 1},
+		"control":            {input: "\x01", width: 0},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if width := Width(tc.input); width != tc.width {
+				t.Errorf("expected %v got %v", tc.width, width)
+			}
+		})
+	}
+}
+
+func TestColumn(t *testing.T) {
+	tests := map[string]struct {
+		line     string
+		tabWidth int
+		column   int
+	}{
+		"no tabs":          {line: "abc", tabWidth: 4, column: 3},
+		"tab":              {line: "\t", tabWidth: 4, column: 4},
+		"tab after text":   {line: "ab\tc", tabWidth: 4, column: 5},
+		"tab at stop":      {line: "abcd\t", tabWidth: 4, column: 8},
+		"two tabs":         {line: "\t\t", tabWidth: 4, column: 8},
+		"default tabs":     {line: "a\t", tabWidth: 0, column: DefaultTabWidth},
+		"wide then tab":    {line: "世\t", tabWidth: 4, column: 4},
+		"combining in tab": {line: "e\u0301\tx", tabWidth: 4, column: 5},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if column := Column(tc.line, tc.tabWidth); column != tc.column {
+				t.Errorf("expected %v got %v", tc.column, column)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Package input decodes the bytes of a file into the UTF-8 the parsers expect. A byte order mark
+(BOM) is used to detect UTF-8 and UTF-16 (little and big endian) input, other encodings, like
+ISO-8859-1 (Latin-1), have to be asked for.
+
+The decoded Source keeps track of where each rune came from, so an offset in the decoded input,
+like parse.State.Index, can be mapped back to the offset in the original bytes with OriginalOffset.
+
+	state, src, err := input.File(parser, "notes.gdtxt", input.Auto)
+	if err == nil && state.IsError {
+		fmt.Printf("error at byte %v of the file: %v", src.OriginalOffset(state.Index), state.Err)
+	}
+*/
+package input
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/gdey/ppc/parse"
+)
+
+// Encoding of the original input
+type Encoding uint8
+
+const (
+	// Auto will detect the encoding from the BOM, defaulting to UTF8
+	Auto Encoding = iota
+	UTF8
+	UTF16LE
+	UTF16BE
+	// Latin1 is ISO-8859-1
+	Latin1
+)
+
+func (enc Encoding) String() string {
+	switch enc {
+	case Auto:
+		return "auto"
+	case UTF8:
+		return "UTF-8"
+	case UTF16LE:
+		return "UTF-16LE"
+	case UTF16BE:
+		return "UTF-16BE"
+	case Latin1:
+		return "ISO-8859-1"
+	}
+	return "unknown"
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// Detect returns the encoding given by the byte order mark at the start of b,
+// and the length of the mark. If there is no mark UTF8 is returned with a length of 0.
+func Detect(b []byte) (enc Encoding, bomLen int) {
+	switch {
+	case bytes.HasPrefix(b, bomUTF8):
+		return UTF8, len(bomUTF8)
+	case bytes.HasPrefix(b, bomUTF16LE):
+		return UTF16LE, len(bomUTF16LE)
+	case bytes.HasPrefix(b, bomUTF16BE):
+		return UTF16BE, len(bomUTF16BE)
+	}
+	return UTF8, 0
+}
+
+// segment is a run of runes that all have the same size in the decoded and original input
+type segment struct {
+	offset   int64 // in the decoded input
+	original int64 // in the original input
+	size     int64 // decoded size of each rune
+	origSize int64 // original size of each rune
+}
+
+// Source is the decoded input, it's an io.ReaderAt so it can be used as parse.State.Source
+type Source struct {
+	data     []byte
+	encoding Encoding
+	segments []segment
+}
+
+// Decode will decode b using the given encoding. With Auto, the encoding is detected
+// using Detect. A BOM for the encoding is skipped.
+// Invalid input (e.g. unpaired UTF-16 surrogates) is decoded as U+FFFD.
+func Decode(b []byte, enc Encoding) *Source {
+	detected, bomLen := Detect(b)
+	if enc == Auto {
+		enc = detected
+	}
+	if enc != detected {
+		bomLen = 0
+	}
+	src := &Source{encoding: enc}
+	switch enc {
+	case UTF16LE, UTF16BE:
+		src.decodeUTF16(b, bomLen, enc == UTF16BE)
+	case Latin1:
+		src.decodeLatin1(b)
+	default:
+		src.data = b[bomLen:]
+		src.segments = []segment{{offset: 0, original: int64(bomLen), size: 1, origSize: 1}}
+	}
+	return src
+}
+
+// add records that a rune of size bytes, came from origSize bytes at original
+func (src *Source) add(r rune, original int64, origSize int64) {
+	offset := int64(len(src.data))
+	src.data = utf8.AppendRune(src.data, r)
+	size := int64(len(src.data)) - offset
+	if n := len(src.segments); n > 0 {
+		last := src.segments[n-1]
+		count := (offset - last.offset) / last.size
+		if last.size == size && last.origSize == origSize && last.original+count*last.origSize == original {
+			return
+		}
+	}
+	src.segments = append(src.segments, segment{
+		offset:   offset,
+		original: original,
+		size:     size,
+		origSize: origSize,
+	})
+}
+
+func (src *Source) decodeUTF16(b []byte, start int, bigEndian bool) {
+	src.data = make([]byte, 0, len(b))
+	unit := func(i int) rune {
+		if bigEndian {
+			return rune(b[i])<<8 | rune(b[i+1])
+		}
+		return rune(b[i+1])<<8 | rune(b[i])
+	}
+	i := start
+	for ; i+1 < len(b); i += 2 {
+		r := unit(i)
+		if utf16.IsSurrogate(r) && i+3 < len(b) {
+			if dr := utf16.DecodeRune(r, unit(i+2)); dr != utf8.RuneError {
+				src.add(dr, int64(i), 4)
+				i += 2
+				continue
+			}
+		}
+		if utf16.IsSurrogate(r) {
+			r = utf8.RuneError
+		}
+		src.add(r, int64(i), 2)
+	}
+	if i < len(b) {
+		// odd trailing byte
+		src.add(utf8.RuneError, int64(i), 1)
+	}
+}
+
+func (src *Source) decodeLatin1(b []byte) {
+	src.data = make([]byte, 0, len(b))
+	for i, c := range b {
+		src.add(rune(c), int64(i), 1)
+	}
+}
+
+// ReadAt reads the decoded input
+func (src *Source) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, io.EOF
+	}
+	return bytes.NewReader(src.data).ReadAt(p, off)
+}
+
+// Len is the length of the decoded input
+func (src *Source) Len() int64 { return int64(len(src.data)) }
+
+// Bytes returns the decoded input
+func (src *Source) Bytes() []byte { return src.data }
+
+// Encoding is the encoding the input was decoded from
+func (src *Source) Encoding() Encoding { return src.encoding }
+
+// OriginalOffset maps an offset in the decoded input to the offset in the original input.
+// Offsets in the middle of a rune are mapped to the start of the original rune, and
+// offsets at or past the end are mapped past the end of the original input.
+func (src *Source) OriginalOffset(offset int64) int64 {
+	if len(src.segments) == 0 {
+		return offset
+	}
+	i := sort.Search(len(src.segments), func(i int) bool { return src.segments[i].offset > offset }) - 1
+	if i < 0 {
+		i = 0
+	}
+	seg := src.segments[i]
+	count := (offset - seg.offset) / seg.size
+	return seg.original + count*seg.origSize
+}
+
+// Read reads all of r and decodes it
+func Read(r io.Reader, enc Encoding) (*Source, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(b, enc), nil
+}
+
+// Bytes will decode b and run the parser over it
+func Bytes(parser parse.Parser, b []byte, enc Encoding) (parse.State, *Source) {
+	src := Decode(b, enc)
+	return parser.Run(parse.State{Source: src}), src
+}
+
+// File will decode the file and run the parser over it
+func File(parser parse.Parser, filename string, enc Encoding) (parse.State, *Source, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return parse.State{}, nil, err
+	}
+	defer f.Close()
+	src, err := Read(f, enc)
+	if err != nil {
+		return parse.State{}, nil, err
+	}
+	return parser.Run(parse.State{Source: src}), src, nil
+}
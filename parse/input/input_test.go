@@ -0,0 +1,127 @@
+package input
+
+import (
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := map[string]struct {
+		input    []byte
+		enc      Encoding
+		encoding Encoding
+		text     string
+		// offsets maps offsets in the decoded text to the original offsets
+		offsets map[int64]int64
+	}{
+		"utf8": {
+			input:    []byte("héllo"),
+			encoding: UTF8,
+			text:     "héllo",
+			offsets:  map[int64]int64{0: 0, 2: 2, 6: 6},
+		},
+		"utf8 bom": {
+			input:    []byte("\xEF\xBB\xBFab"),
+			encoding: UTF8,
+			text:     "ab",
+			offsets:  map[int64]int64{0: 3, 1: 4, 2: 5},
+		},
+		"utf16le bom surrogate pair": {
+			// BOM, "a", U+1F600 as a surrogate pair, "b"
+			input:    []byte{0xFF, 0xFE, 'a', 0, 0x3D, 0xD8, 0x00, 0xDE, 'b', 0},
+			encoding: UTF16LE,
+			text:     "a\U0001F600b",
+			offsets:  map[int64]int64{0: 2, 1: 4, 3: 4, 5: 8, 6: 10},
+		},
+		"utf16be explicit": {
+			input:    []byte{0, 'a', 0xD8, 0x3D, 0xDE, 0x00, 0, 'b'},
+			enc:      UTF16BE,
+			encoding: UTF16BE,
+			text:     "a\U0001F600b",
+			offsets:  map[int64]int64{0: 0, 1: 2, 5: 6, 6: 8},
+		},
+		"utf16le unpaired surrogate": {
+			input:    []byte{0x3D, 0xD8, 'a', 0},
+			enc:      UTF16LE,
+			encoding: UTF16LE,
+			text:     "\uFFFDa",
+			offsets:  map[int64]int64{0: 0, 3: 2, 4: 4},
+		},
+		"utf16le odd byte": {
+			input:    []byte{'a', 0, 'b'},
+			enc:      UTF16LE,
+			encoding: UTF16LE,
+			text:     "a\uFFFD",
+			offsets:  map[int64]int64{0: 0, 1: 2, 4: 3},
+		},
+		"latin1": {
+			input:    []byte("caf\xE9!"),
+			enc:      Latin1,
+			encoding: Latin1,
+			text:     "café!",
+			offsets:  map[int64]int64{2: 2, 3: 3, 4: 3, 5: 4, 6: 5},
+		},
+		"latin1 with utf8 bom": {
+			// the BOM is not skipped, it's three Latin-1 characters
+			input:    []byte("\xEF\xBB\xBFa"),
+			enc:      Latin1,
+			encoding: Latin1,
+			text:     "ï»¿a",
+			offsets:  map[int64]int64{0: 0, 2: 1, 6: 3, 7: 4},
+		},
+		"utf16be with utf16le bom": {
+			// the LE BOM read as big endian is U+FFFE, and is kept
+			input:    []byte{0xFF, 0xFE, 0, 'a'},
+			enc:      UTF16BE,
+			encoding: UTF16BE,
+			text:     "\uFFFEa",
+			offsets:  map[int64]int64{0: 0, 3: 2, 4: 4},
+		},
+		"utf8 with utf16le bom": {
+			input:    []byte{0xFF, 0xFE, 'a'},
+			enc:      UTF8,
+			encoding: UTF8,
+			text:     "\xFF\xFEa",
+			offsets:  map[int64]int64{0: 0, 2: 2},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			src := Decode(tc.input, tc.enc)
+			if src.Encoding() != tc.encoding {
+				t.Errorf("encoding, expected %v got %v", tc.encoding, src.Encoding())
+			}
+			if string(src.Bytes()) != tc.text {
+				t.Errorf("text, expected %q got %q", tc.text, src.Bytes())
+			}
+			if src.Len() != int64(len(tc.text)) {
+				t.Errorf("len, expected %v got %v", len(tc.text), src.Len())
+			}
+			for offset, original := range tc.offsets {
+				if got := src.OriginalOffset(offset); got != original {
+					t.Errorf("original offset of %v, expected %v got %v", offset, original, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := map[string]struct {
+		input  []byte
+		enc    Encoding
+		bomLen int
+	}{
+		"none":    {input: []byte("a"), enc: UTF8, bomLen: 0},
+		"utf8":    {input: []byte{0xEF, 0xBB, 0xBF}, enc: UTF8, bomLen: 3},
+		"utf16le": {input: []byte{0xFF, 0xFE, 'a', 0}, enc: UTF16LE, bomLen: 2},
+		"utf16be": {input: []byte{0xFE, 0xFF, 0, 'a'}, enc: UTF16BE, bomLen: 2},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			enc, bomLen := Detect(tc.input)
+			if enc != tc.enc || bomLen != tc.bomLen {
+				t.Errorf("expected %v, %v got %v, %v", tc.enc, tc.bomLen, enc, bomLen)
+			}
+		})
+	}
+}
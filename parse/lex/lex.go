@@ -0,0 +1,184 @@
+/*
+Package lex splits an input into tokens using parsers, so a grammar can then be written
+against the kinds of tokens instead of the characters.
+
+	lexer := lex.Lexer{
+		Rules: []lex.Rule{
+			{Kind: "space", Parser: match.Runes(unicode.IsSpace, errors.New("expected space")), Skip: true},
+			{Kind: "number", Parser: match.Integer()},
+			{Kind: "ident", Parser: match.Letters()},
+			{Kind: "op", Parser: match.OneOf("+", "-", "*", "/", "(", ")")},
+		},
+	}
+	tokens, err := lexer.String("a + 12")
+	state := parse.Items(
+		parse.SequenceOf(lex.Kind("ident"), lex.Text("+"), lex.Kind("number")),
+		tokens,
+	)
+*/
+package lex
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdey/ppc/ast"
+	"github.com/gdey/ppc/parse"
+)
+
+// Token is a piece of the input, as matched by one of the lexer's rules
+type Token struct {
+	Kind string
+	Text string
+	// Value is the result of the rule's parser
+	Value interface{}
+	// Start and End are the byte offsets of the token in the input
+	Start int64
+	End   int64
+}
+
+func (t Token) Span() ast.Span       { return ast.Span{Start: t.Start, End: t.End} }
+func (t Token) Children() []ast.Node { return nil }
+
+func (t Token) String() string { return fmt.Sprintf("%v(%q)", t.Kind, t.Text) }
+
+// Tokens is a parse.Stream of tokens
+type Tokens []Token
+
+func (t Tokens) Len() int64             { return int64(len(t)) }
+func (t Tokens) At(i int64) interface{} { return t[i] }
+
+// Rule describes one kind of token
+type Rule struct {
+	Kind   string
+	Parser parse.Parser
+	// Skip will drop the token from the output, e.g. for whitespace and comments
+	Skip bool
+}
+
+// Lexer turns an input into tokens
+type Lexer struct {
+	// Rules are tried at each position of the input, the rule with the longest
+	// match wins. If two rules match the same length, the first one wins.
+	Rules []Rule
+}
+
+// Error is returned when none of the rules match
+type Error struct {
+	Offset int64
+	Line   int
+	Column int
+	// Near is the text at the offset
+	Near string
+}
+
+func (err Error) Error() string {
+	return fmt.Sprintf("no token matches at %v:%v near %q", err.Line, err.Column, err.Near)
+}
+
+// Tokenize will split the state's source into tokens, starting at the state's index
+func (lexer Lexer) Tokenize(state parse.State) (Tokens, error) {
+	var tokens Tokens
+	for {
+		if end := parse.EndOfInput().Run(state); !end.IsError {
+			return tokens, nil
+		}
+		var (
+			best     parse.State
+			bestRule = -1
+		)
+		for i, rule := range lexer.Rules {
+			next := rule.Parser.Run(state)
+			if next.IsError || next.Index <= state.Index {
+				continue
+			}
+			if bestRule == -1 || next.Index > best.Index {
+				best, bestRule = next, i
+			}
+		}
+		if bestRule == -1 {
+			return tokens, lexer.errorAt(state)
+		}
+		if rule := lexer.Rules[bestRule]; !rule.Skip {
+			text := make([]byte, best.Index-state.Index)
+			if n, err := state.Source.ReadAt(text, state.Index); n != len(text) {
+				return tokens, err
+			}
+			tokens = append(tokens, Token{
+				Kind:  rule.Kind,
+				Text:  string(text),
+				Value: best.Result,
+				Start: state.Index,
+				End:   best.Index,
+			})
+		}
+		state = state.WithResult(nil, best.Index)
+	}
+}
+
+func (lexer Lexer) errorAt(state parse.State) error {
+	line, column, _ := state.LineColumn(0)
+	buff, n, _ := state.ReadNextBytes(16)
+	return Error{
+		Offset: state.Index,
+		Line:   line,
+		Column: column,
+		Near:   strings.ToValidUTF8(string(buff[:n]), ""),
+	}
+}
+
+// String will split the string into tokens
+func (lexer Lexer) String(s string) (Tokens, error) {
+	return lexer.Tokenize(parse.State{Source: strings.NewReader(s)})
+}
+
+// Kind matches a token of one of the given kinds
+// result is a Token
+func Kind(kinds ...string) parse.Parser {
+	return parse.Item(
+		func(item interface{}) bool {
+			tok, ok := item.(Token)
+			if !ok {
+				return false
+			}
+			for _, kind := range kinds {
+				if tok.Kind == kind {
+					return true
+				}
+			}
+			return false
+		},
+		fmt.Errorf("expected token of kind %v", strings.Join(kinds, ", ")),
+	)
+}
+
+// Text matches a token whose text is one of the given strings
+// result is a Token
+func Text(texts ...string) parse.Parser {
+	return parse.Item(
+		func(item interface{}) bool {
+			tok, ok := item.(Token)
+			if !ok {
+				return false
+			}
+			for _, text := range texts {
+				if tok.Text == text {
+					return true
+				}
+			}
+			return false
+		},
+		fmt.Errorf("expected token %v", strings.Join(texts, ", ")),
+	)
+}
+
+// Value will replace the resulting Token of the parser with the token's Value
+func Value(parser parse.Parser) parse.Parser {
+	return parse.Map(parser, func(r interface{}) interface{} {
+		tok, ok := r.(Token)
+		if !ok {
+			return r
+		}
+		return tok.Value
+	})
+}
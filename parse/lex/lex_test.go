@@ -0,0 +1,122 @@
+package lex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"unicode"
+
+	"github.com/gdey/ppc/parse"
+	"github.com/gdey/ppc/parse/match"
+)
+
+var testLexer = Lexer{
+	Rules: []Rule{
+		{Kind: "space", Parser: match.Runes(unicode.IsSpace, errors.New("expected space")), Skip: true},
+		{Kind: "keyword", Parser: match.OneOf("if", "else")},
+		{Kind: "ident", Parser: match.Letters()},
+		{Kind: "number", Parser: match.Integer()},
+		{Kind: "op", Parser: match.OneOf("=", "==", "+")},
+	},
+}
+
+func kinds(tokens Tokens) []string {
+	var ks []string
+	for _, tok := range tokens {
+		ks = append(ks, tok.Kind+":"+tok.Text)
+	}
+	return ks
+}
+
+func TestTokenize(t *testing.T) {
+	tests := map[string]struct {
+		lexer  Lexer
+		input  string
+		tokens []string
+	}{
+		"skip":           {lexer: testLexer, input: "  a  =\n 1 ", tokens: []string{"ident:a", "op:=", "number:1"}},
+		"first rule":     {lexer: testLexer, input: "if x", tokens: []string{"keyword:if", "ident:x"}},
+		"longest rule":   {lexer: testLexer, input: "iffy", tokens: []string{"ident:iffy"}},
+		"longest op":     {lexer: testLexer, input: "a==b", tokens: []string{"ident:a", "op:==", "ident:b"}},
+		"empty":          {lexer: testLexer, input: "", tokens: nil},
+		"only skipped":   {lexer: testLexer, input: " \n ", tokens: nil},
+		"rules in order": {lexer: Lexer{Rules: testLexer.Rules[2:]}, input: "if", tokens: []string{"ident:if"}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tokens, err := tc.lexer.String(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := kinds(tokens); !reflect.DeepEqual(got, tc.tokens) {
+				t.Errorf("expected %q got %q", tc.tokens, got)
+			}
+		})
+	}
+
+	tokens, err := testLexer.String(" ab = 12")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Tokens{
+		{Kind: "ident", Text: "ab", Value: "ab", Start: 1, End: 3},
+		{Kind: "op", Text: "=", Value: "=", Start: 4, End: 5},
+		{Kind: "number", Text: "12", Value: int64(12), Start: 6, End: 8},
+	}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("expected %v got %v", expected, tokens)
+	}
+}
+
+func TestTokenizeError(t *testing.T) {
+	tokens, err := testLexer.String("a = 1\n  b $ c")
+	var lerr Error
+	if !errors.As(err, &lerr) {
+		t.Fatalf("expected an Error got %v", err)
+	}
+	expected := Error{Offset: 10, Line: 2, Column: 5, Near: "$ c"}
+	if lerr != expected {
+		t.Errorf("expected %#v got %#v", expected, lerr)
+	}
+	if msg := `no token matches at 2:5 near "$ c"`; err.Error() != msg {
+		t.Errorf("expected %q got %q", msg, err.Error())
+	}
+	// the tokens before the error are returned
+	if got := kinds(tokens); !reflect.DeepEqual(got, []string{"ident:a", "op:=", "number:1", "ident:b"}) {
+		t.Errorf("expected the tokens before the error, got %q", got)
+	}
+}
+
+func TestParseTokens(t *testing.T) {
+	tokens, err := testLexer.String("a = 12")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assignment := parse.SequenceOf(Kind("ident"), Text("="), Value(Kind("number")), parse.EndOfInput())
+	state := parse.Items(assignment, tokens)
+	if state.IsError {
+		t.Fatalf("unexpected error: %v", state.Err)
+	}
+	results := state.Result.([]interface{})
+	if results[0].(Token).Text != "a" || results[2] != int64(12) || state.Index != 3 {
+		t.Errorf("expected a and 12 at 3 got %v at %v", results, state.Index)
+	}
+
+	// EndOfInput fails when there are tokens left
+	state = parse.Items(parse.SequenceOf(Kind("ident"), parse.EndOfInput()), tokens)
+	if !state.IsError {
+		t.Errorf("expected an error with tokens left")
+	}
+	// and matches on an empty stream
+	if state := parse.Items(parse.EndOfInput(), Tokens{}); state.IsError {
+		t.Errorf("unexpected error: %v", state.Err)
+	}
+
+	if state := parse.Items(Kind("number", "ident"), tokens); state.IsError {
+		t.Errorf("expected ident to match one of number, ident: %v", state.Err)
+	}
+	state = parse.Items(Kind("number"), tokens)
+	if state.Err == nil || state.Err.Error() != "expected token of kind number" {
+		t.Errorf("expected kind error, got %v", state.Err)
+	}
+}
@@ -0,0 +1,259 @@
+// Package lexeme helps write parsers that work at the token level, where whitespace and
+// comments (trivia) between the tokens are not significant.
+//
+// A Language describes what the trivia is, and Lexeme wraps a parser so that it will skip any
+// trivia after its match. Since every token skips the trivia after it, only the trivia at the
+// start of the input needs to be skipped; which is what Language.Program does.
+//
+//	var lang = lexeme.Language{
+//		LineComment:       "//",
+//		BlockCommentStart: "/*",
+//		BlockCommentEnd:   "*/",
+//		NestedComments:    true,
+//	}
+//
+//	var assignment = parse.SequenceOf(
+//		lang.Identifier(),
+//		lang.Symbol("="),
+//		lang.Lexeme(match.Integer()),
+//		lang.Symbol(";"),
+//	)
+//
+//	parse.String(lang.Program(parse.Many(assignment)), "a = 1; // one\n b /* two */ = 2;")
+package lexeme
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+
+	"github.com/gdey/ppc/parse"
+	"github.com/gdey/ppc/parse/match"
+)
+
+// ErrUnterminatedComment is returned when the end of input is reached inside of a block comment
+var ErrUnterminatedComment = errors.New("unterminated comment")
+
+// Language describes the trivia and identifiers of a language
+// The zero value is a language with unicode whitespace as the only trivia,
+// and identifiers that are a letter or '_' followed by letters, digits or '_'
+type Language struct {
+	// Whitespace matches one whitespace element, if nil match.Space() is used
+	Whitespace parse.Parser
+	// LineComment starts a comment that goes till the end of the line (e.g. "//", "#", "--")
+	// empty means there are no line comments
+	LineComment string
+	// BlockCommentStart and BlockCommentEnd surround a block comment (e.g. "/*" and "*/")
+	// empty means there are no block comments
+	BlockCommentStart string
+	BlockCommentEnd   string
+	// NestedComments allows block comments to contain block comments
+	NestedComments bool
+
+	// IdentStart and IdentLetter describe the runes of an identifier
+	IdentStart  func(rune) bool
+	IdentLetter func(rune) bool
+	// Reserved words will not be matched as identifiers
+	Reserved []string
+	// CaseFold will ignore case when matching reserved words and symbols
+	CaseFold bool
+}
+
+func (lang Language) whitespace() parse.Parser {
+	if lang.Whitespace != nil {
+		return lang.Whitespace
+	}
+	return match.Space()
+}
+
+// lineComment matches the line comment up to, but not including, the end of the line
+func (lang Language) lineComment() parse.Parser {
+	return parse.Discard(
+		parse.SequenceOf(
+			match.String(lang.LineComment),
+			parse.Optional(match.Runes(
+				func(r rune) bool { return !parse.IsNewline(r) },
+				errors.New("comment text"),
+			)),
+		),
+	)
+}
+
+// blockComment matches a block comment, with nested comments if enabled
+func (lang Language) blockComment() parse.Parser {
+	start := match.String(lang.BlockCommentStart)
+	end := match.String(lang.BlockCommentEnd)
+	return parse.Func(func(state parse.State) parse.State {
+		cstate := start.Run(state)
+		if cstate.IsError {
+			return cstate
+		}
+		depth := 1
+		for depth > 0 {
+			if next := end.Run(cstate); !next.IsError {
+				depth--
+				cstate = next
+				continue
+			}
+			if lang.NestedComments {
+				if next := start.Run(cstate); !next.IsError {
+					depth++
+					cstate = next
+					continue
+				}
+			}
+			_, n, err := cstate.ReadNextRune()
+			if err != nil {
+				return state.WithError(fmt.Errorf("%w starting at %v", ErrUnterminatedComment, state.Index))
+			}
+			cstate.Index += int64(n)
+		}
+		return cstate.WithResult(nil, cstate.Index)
+	})
+}
+
+// Trivia matches zero or more whitespace and comments
+// A block comment without an end is an error wrapping ErrUnterminatedComment.
+// result is nil
+func (lang Language) Trivia() parse.Parser {
+	choices := []parse.Parser{lang.whitespace()}
+	if lang.LineComment != "" {
+		choices = append(choices, lang.lineComment())
+	}
+	if lang.BlockCommentStart != "" && lang.BlockCommentEnd != "" {
+		choices = append(choices, lang.blockComment())
+	}
+	return parse.Func(func(state parse.State) parse.State {
+	trivia:
+		for {
+			for _, choice := range choices {
+				next := choice.Run(state)
+				if !next.IsError {
+					state = next
+					continue trivia
+				}
+				if parse.IsInvalidUTF8(next.Err) || errors.Is(next.Err, ErrUnterminatedComment) {
+					return next
+				}
+			}
+			return state.WithResult(nil, state.Index)
+		}
+	})
+}
+
+// Lexeme will match the parser and then skip any trivia after it
+// result is the result of parser
+func (lang Language) Lexeme(parser parse.Parser) parse.Parser {
+	trivia := lang.Trivia()
+	return parse.Func(func(state parse.State) parse.State {
+		next := parser.Run(state)
+		if next.IsError {
+			return next
+		}
+		after := trivia.Run(next)
+		if after.IsError {
+			// an unterminated comment goes to the end of input, so it is left for Program to report
+			return next
+		}
+		return after.WithResult(next.Result, after.Index)
+	})
+}
+
+// Program will skip any leading trivia, then match the parser, and expect the end of input
+// An unterminated comment is reported as an error wrapping ErrUnterminatedComment.
+// result is the result of parser
+func (lang Language) Program(parser parse.Parser) parse.Parser {
+	return parse.Map(
+		parse.SequenceOf(
+			lang.Trivia(),
+			parser,
+			lang.Trivia(),
+			parse.EndOfInput(),
+		),
+		func(r interface{}) interface{} { return r.([]interface{})[1] },
+	)
+}
+
+// Symbol matches the string followed by trivia
+// result is the string
+func (lang Language) Symbol(s string) parse.Parser {
+	if lang.CaseFold {
+		return lang.Lexeme(match.StringInsensitive(s))
+	}
+	return lang.Lexeme(match.String(s))
+}
+
+// Symbols matches the longest of the given symbols followed by trivia
+// result is the symbol that matched
+func (lang Language) Symbols(symbols ...string) parse.Parser {
+	return lang.Lexeme(match.Words(lang.CaseFold, nil, symbols...))
+}
+
+func (lang Language) identLetter() func(rune) bool {
+	if lang.IdentLetter != nil {
+		return lang.IdentLetter
+	}
+	return match.IsWordRune
+}
+
+// ReservedWord matches one of the given reserved words, that is not followed by an identifier rune,
+// followed by trivia.
+// result is the word as given
+func (lang Language) ReservedWord(words ...string) parse.Parser {
+	return lang.Lexeme(match.Words(lang.CaseFold, lang.identLetter(), words...))
+}
+
+// Identifier matches an identifier that is not a reserved word, followed by trivia
+// result is a string
+func (lang Language) Identifier() parse.Parser {
+	start := lang.IdentStart
+	if start == nil {
+		start = func(r rune) bool { return r == '_' || unicode.IsLetter(r) }
+	}
+	letter := lang.identLetter()
+	var reserved parse.Parser
+	if len(lang.Reserved) > 0 {
+		reserved = match.Words(lang.CaseFold, letter, lang.Reserved...)
+	}
+	return lang.Lexeme(parse.Func(func(state parse.State) parse.State {
+		if reserved != nil {
+			if next := reserved.Run(state); !next.IsError {
+				return state.WithError(fmt.Errorf("unexpected reserved word %v at %v", next.Result, state.Index))
+			}
+		}
+		r, n, err := state.ReadNextRune()
+		if err != nil || !start(r) {
+			return state.WithError(fmt.Errorf("expected identifier at %v", state.Index))
+		}
+		runes := []rune{r}
+		cstate := state
+		cstate.Index += int64(n)
+		for {
+			r, n, err := cstate.ReadNextRune()
+			if err != nil || !letter(r) {
+				break
+			}
+			runes = append(runes, r)
+			cstate.Index += int64(n)
+		}
+		return state.WithResult(parse.RunesToString(runes), cstate.Index)
+	}))
+}
+
+// Parens matches the parser between '(' and ')'
+// result is the result of parser
+func (lang Language) Parens(parser parse.Parser) parse.Parser {
+	return parse.Between(lang.Symbol("("), lang.Symbol(")"))(parser)
+}
+
+// Brackets matches the parser between '[' and ']'
+// result is the result of parser
+func (lang Language) Brackets(parser parse.Parser) parse.Parser {
+	return parse.Between(lang.Symbol("["), lang.Symbol("]"))(parser)
+}
+
+// Braces matches the parser between '{' and '}'
+// result is the result of parser
+func (lang Language) Braces(parser parse.Parser) parse.Parser {
+	return parse.Between(lang.Symbol("{"), lang.Symbol("}"))(parser)
+}
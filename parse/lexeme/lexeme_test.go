@@ -0,0 +1,171 @@
+package lexeme
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gdey/ppc/parse"
+	"github.com/gdey/ppc/parse/match"
+)
+
+var testLang = Language{
+	LineComment:       "//",
+	BlockCommentStart: "/*",
+	BlockCommentEnd:   "*/",
+	NestedComments:    true,
+	Reserved:          []string{"if", "else"},
+}
+
+func TestTrivia(t *testing.T) {
+	flat := testLang
+	flat.NestedComments = false
+	tests := map[string]struct {
+		lang   Language
+		input  string
+		result []interface{}
+		err    error
+	}{
+		"whitespace":         {lang: testLang, input: "  a \n\t b  ", result: []interface{}{"a", "b"}},
+		"line comments":      {lang: testLang, input: "// start\na // one\nb // end", result: []interface{}{"a", "b"}},
+		"block comments":     {lang: testLang, input: "/* start */ a /* one\ntwo */b/**/", result: []interface{}{"a", "b"}},
+		"nested comments":    {lang: testLang, input: "a /* x /* y */ z */ b", result: []interface{}{"a", "b"}},
+		"flat comments":      {lang: flat, input: "a /* x /* y */ b", result: []interface{}{"a", "b"}},
+		"unterminated":       {lang: testLang, input: "a /* b", err: ErrUnterminatedComment},
+		"unterminated inner": {lang: testLang, input: "a /* b /* c */", err: ErrUnterminatedComment},
+		"unterminated start": {lang: testLang, input: "/* a", err: ErrUnterminatedComment},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.String(tc.lang.Program(parse.Many(tc.lang.Identifier())), tc.input)
+			if tc.err != nil {
+				if !errors.Is(state.Err, tc.err) {
+					t.Fatalf("expected %v got %v", tc.err, state.Err)
+				}
+				return
+			}
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			if !reflect.DeepEqual(state.Result, tc.result) {
+				t.Errorf("expected %q got %q", tc.result, state.Result)
+			}
+		})
+	}
+
+	// a nested comment is not the end of a flat comment
+	state := parse.String(flat.Program(parse.Many(flat.Identifier())), "a /* x /* y */ z */ b")
+	if !state.IsError {
+		t.Errorf("expected the */ after z to be an error, got %q", state.Result)
+	}
+	// the error is at the start of the comment
+	state = parse.String(testLang.Program(parse.Many(testLang.Identifier())), "a  /* b")
+	if expected := "unterminated comment starting at 3"; state.Err == nil || state.Err.Error() != expected {
+		t.Errorf("expected %q got %v", expected, state.Err)
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	tests := map[string]struct {
+		parser parse.Parser
+		input  string
+		result interface{}
+		index  int64
+		err    bool
+	}{
+		"symbol":             {parser: testLang.Symbol("="), input: "= 1", result: "=", index: 2},
+		"symbol in longer":   {parser: testLang.Symbol("="), input: "==", result: "=", index: 1},
+		"longest":            {parser: testLang.Symbols("=", "==", "=>"), input: "== 1", result: "==", index: 3},
+		"longest other":      {parser: testLang.Symbols("=", "==", "=>"), input: "=>>", result: "=>", index: 2},
+		"shorter at the end": {parser: testLang.Symbols("=", "==", "==="), input: "=", result: "=", index: 1},
+		"shorter before":     {parser: testLang.Symbols("<", "<<=", "<="), input: "<<", result: "<", index: 1},
+		"no symbol":          {parser: testLang.Symbols("=", "=="), input: "!=", err: true},
+		"case fold":          {parser: Language{CaseFold: true}.Symbol("AND"), input: "and b", result: "AND", index: 4},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.String(tc.parser, tc.input)
+			if tc.err {
+				if !state.IsError {
+					t.Fatalf("expected error, got %q", state.Result)
+				}
+				return
+			}
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			if state.Result != tc.result || state.Index != tc.index {
+				t.Errorf("expected %q at %v got %q at %v", tc.result, tc.index, state.Result, state.Index)
+			}
+		})
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	fold := testLang
+	fold.CaseFold = true
+	tests := map[string]struct {
+		lang   Language
+		input  string
+		result interface{}
+		index  int64
+		err    string
+	}{
+		"identifier":         {lang: testLang, input: "abc def", result: "abc", index: 4},
+		"underscore start":   {lang: testLang, input: "_a1", result: "_a1", index: 3},
+		"digit start":        {lang: testLang, input: "1a", err: "expected identifier at 0"},
+		"unicode":            {lang: testLang, input: "café=", result: "café", index: 5},
+		"reserved":           {lang: testLang, input: "if x", err: "unexpected reserved word if at 0"},
+		"reserved at end":    {lang: testLang, input: "else", err: "unexpected reserved word else at 0"},
+		"reserved prefix":    {lang: testLang, input: "iffy", result: "iffy", index: 4},
+		"reserved and more":  {lang: testLang, input: "if_x", result: "if_x", index: 4},
+		"reserved then rune": {lang: testLang, input: "if(x)", err: "unexpected reserved word if at 0"},
+		"case sensitive":     {lang: testLang, input: "IF", result: "IF", index: 2},
+		"case fold":          {lang: fold, input: "IF", err: "unexpected reserved word if at 0"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.String(tc.lang.Identifier(), tc.input)
+			if tc.err != "" {
+				if state.Err == nil || state.Err.Error() != tc.err {
+					t.Fatalf("expected error %q got %v", tc.err, state.Err)
+				}
+				return
+			}
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			if state.Result != tc.result || state.Index != tc.index {
+				t.Errorf("expected %q at %v got %q at %v", tc.result, tc.index, state.Result, state.Index)
+			}
+		})
+	}
+
+	state := parse.String(testLang.ReservedWord("if", "else"), "else if")
+	if state.IsError || state.Result != "else" || state.Index != 5 {
+		t.Errorf("expected else at 5 got %q at %v (%v)", state.Result, state.Index, state.Err)
+	}
+	if state := parse.String(testLang.ReservedWord("if"), "iffy"); !state.IsError {
+		t.Errorf("expected if not to match in iffy, got %q", state.Result)
+	}
+}
+
+func TestLexemeProgram(t *testing.T) {
+	assignment := parse.SequenceOf(
+		testLang.Identifier(),
+		testLang.Symbol("="),
+		testLang.Lexeme(match.Integer()),
+		testLang.Symbol(";"),
+	)
+	state := parse.String(testLang.Program(parse.Many(assignment)), " a = 1; // one\n b /* two */ = 2;\n")
+	if state.IsError {
+		t.Fatalf("unexpected error: %v", state.Err)
+	}
+	expected := []interface{}{
+		[]interface{}{"a", "=", int64(1), ";"},
+		[]interface{}{"b", "=", int64(2), ";"},
+	}
+	if !reflect.DeepEqual(state.Result, expected) {
+		t.Errorf("expected %v got %v", expected, state.Result)
+	}
+}
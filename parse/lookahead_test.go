@@ -0,0 +1,64 @@
+package parse
+
+import (
+	"testing"
+)
+
+func TestLookahead(t *testing.T) {
+	tests := map[string]struct {
+		parser Parser
+		input  string
+		result interface{}
+		err    bool
+	}{
+		"and matches":     {parser: And(literal("ab")), input: "abc", result: "ab"},
+		"and fails":       {parser: And(literal("ab")), input: "axc", err: true},
+		"not matches":     {parser: Not(literal("ab")), input: "axc", result: nil},
+		"not fails":       {parser: Not(literal("ab")), input: "abc", err: true},
+		"and at the end":  {parser: And(literal("c")), input: "c", result: "c"},
+		"not at the end":  {parser: Not(literal("c")), input: "", result: nil},
+		"and of not":      {parser: And(Not(literal("x"))), input: "abc", result: nil},
+		"not of and":      {parser: Not(And(literal("a"))), input: "abc", err: true},
+		"and after input": {parser: And(SequenceOf(literal("a"), literal("b"))), input: "abc", result: []interface{}{"a", "b"}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// start after a byte, so the index is not the zero value
+			state := String(SequenceOf(literal(">"), tc.parser), ">"+tc.input)
+			if state.IsError != tc.err {
+				t.Fatalf("error, expected %v got %v", tc.err, state.Err)
+			}
+			if state.Index != 1 && !tc.err {
+				t.Errorf("expected the index to stay at 1 got %v", state.Index)
+			}
+			if tc.err {
+				// the error is at the index it was run at
+				next := tc.parser.Run(State{Source: state.Source, Index: 1})
+				if next.Index != 1 {
+					t.Errorf("expected the error at 1 got %v", next.Index)
+				}
+				return
+			}
+			if result := state.Result.([]interface{})[1]; !equal(result, tc.result) {
+				t.Errorf("expected %#v got %#v", tc.result, result)
+			}
+		})
+	}
+}
+
+func equal(a, b interface{}) bool {
+	as, ok := a.([]interface{})
+	if !ok {
+		return a == b
+	}
+	bs, ok := b.([]interface{})
+	if !ok || len(as) != len(bs) {
+		return false
+	}
+	for i := range as {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/gdey/ppc/parse"
+)
+
+func TestExcept(t *testing.T) {
+	notSpace := Except(AnyRune(), Space())
+	tests := map[string]struct {
+		parser parse.Parser
+		input  string
+		result interface{}
+		index  int64
+		err    bool
+	}{
+		"matches":          {parser: notSpace, input: "ab", result: 'a', index: 1},
+		"excluded":         {parser: notSpace, input: " b", err: true},
+		"p fails":          {parser: Except(String("a"), String("b")), input: "c", err: true},
+		"longer exclusion": {parser: Except(AnyRune(), String("ab")), input: "ac", result: 'a', index: 1},
+		"excluded prefix":  {parser: Except(AnyRune(), String("ab")), input: "abc", err: true},
+		"at the end":       {parser: notSpace, input: "", err: true},
+		"multi byte":       {parser: Except(AnyRune(), String("[")), input: "éa", result: 'é', index: 2},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.String(tc.parser, tc.input)
+			if tc.err {
+				if !state.IsError {
+					t.Fatalf("expected error, got %#v at %v", state.Result, state.Index)
+				}
+				if state.Index != 0 {
+					t.Errorf("expected the index to stay at 0 got %v", state.Index)
+				}
+				return
+			}
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			if state.Result != tc.result || state.Index != tc.index {
+				t.Errorf("expected %#v at %v got %#v at %v", tc.result, tc.index, state.Result, state.Index)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package match
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gdey/ppc/parse"
+)
+
+// fullFolds are all the case foldings from the unicode CaseFolding.txt (version 14.0.0) with
+// status F; those that fold a rune into more than one rune. Along with runes that should not be
+// folded by the simple fold as they only fold in some locales.
+var fullFolds = map[rune][]rune{
+	0x00DF: {'s', 's'},               // ß
+	0x0130: {'i', 0x0307},            // İ
+	0x0131: {0x0131},                 // ı only folds to i in Turkish and Azeri
+	0x0149: {0x02BC, 'n'},            // ŉ
+	0x01F0: {'j', 0x030C},            // ǰ
+	0x0390: {0x03B9, 0x0308, 0x0301}, // ΐ
+	0x03B0: {0x03C5, 0x0308, 0x0301}, // ΰ
+	0x0587: {0x0565, 0x0582},         // և
+	0x1E96: {'h', 0x0331},            // ẖ
+	0x1E97: {'t', 0x0308},            // ẗ
+	0x1E98: {'w', 0x030A},            // ẘ
+	0x1E99: {'y', 0x030A},            // ẙ
+	0x1E9A: {'a', 0x02BE},            // ẚ
+	0x1E9E: {'s', 's'},               // ẞ
+	0x1F50: {0x03C5, 0x0313},         // ὐ
+	0x1F52: {0x03C5, 0x0313, 0x0300}, // ὒ
+	0x1F54: {0x03C5, 0x0313, 0x0301}, // ὔ
+	0x1F56: {0x03C5, 0x0313, 0x0342}, // ὖ
+	0x1F80: {0x1F00, 0x03B9},         // ᾀ
+	0x1F81: {0x1F01, 0x03B9},         // ᾁ
+	0x1F82: {0x1F02, 0x03B9},         // ᾂ
+	0x1F83: {0x1F03, 0x03B9},         // ᾃ
+	0x1F84: {0x1F04, 0x03B9},         // ᾄ
+	0x1F85: {0x1F05, 0x03B9},         // ᾅ
+	0x1F86: {0x1F06, 0x03B9},         // ᾆ
+	0x1F87: {0x1F07, 0x03B9},         // ᾇ
+	0x1F88: {0x1F00, 0x03B9},         // ᾈ
+	0x1F89: {0x1F01, 0x03B9},         // ᾉ
+	0x1F8A: {0x1F02, 0x03B9},         // ᾊ
+	0x1F8B: {0x1F03, 0x03B9},         // ᾋ
+	0x1F8C: {0x1F04, 0x03B9},         // ᾌ
+	0x1F8D: {0x1F05, 0x03B9},         // ᾍ
+	0x1F8E: {0x1F06, 0x03B9},         // ᾎ
+	0x1F8F: {0x1F07, 0x03B9},         // ᾏ
+	0x1F90: {0x1F20, 0x03B9},         // ᾐ
+	0x1F91: {0x1F21, 0x03B9},         // ᾑ
+	0x1F92: {0x1F22, 0x03B9},         // ᾒ
+	0x1F93: {0x1F23, 0x03B9},         // ᾓ
+	0x1F94: {0x1F24, 0x03B9},         // ᾔ
+	0x1F95: {0x1F25, 0x03B9},         // ᾕ
+	0x1F96: {0x1F26, 0x03B9},         // ᾖ
+	0x1F97: {0x1F27, 0x03B9},         // ᾗ
+	0x1F98: {0x1F20, 0x03B9},         // ᾘ
+	0x1F99: {0x1F21, 0x03B9},         // ᾙ
+	0x1F9A: {0x1F22, 0x03B9},         // ᾚ
+	0x1F9B: {0x1F23, 0x03B9},         // ᾛ
+	0x1F9C: {0x1F24, 0x03B9},         // ᾜ
+	0x1F9D: {0x1F25, 0x03B9},         // ᾝ
+	0x1F9E: {0x1F26, 0x03B9},         // ᾞ
+	0x1F9F: {0x1F27, 0x03B9},         // ᾟ
+	0x1FA0: {0x1F60, 0x03B9},         // ᾠ
+	0x1FA1: {0x1F61, 0x03B9},         // ᾡ
+	0x1FA2: {0x1F62, 0x03B9},         // ᾢ
+	0x1FA3: {0x1F63, 0x03B9},         // ᾣ
+	0x1FA4: {0x1F64, 0x03B9},         // ᾤ
+	0x1FA5: {0x1F65, 0x03B9},         // ᾥ
+	0x1FA6: {0x1F66, 0x03B9},         // ᾦ
+	0x1FA7: {0x1F67, 0x03B9},         // ᾧ
+	0x1FA8: {0x1F60, 0x03B9},         // ᾨ
+	0x1FA9: {0x1F61, 0x03B9},         // ᾩ
+	0x1FAA: {0x1F62, 0x03B9},         // ᾪ
+	0x1FAB: {0x1F63, 0x03B9},         // ᾫ
+	0x1FAC: {0x1F64, 0x03B9},         // ᾬ
+	0x1FAD: {0x1F65, 0x03B9},         // ᾭ
+	0x1FAE: {0x1F66, 0x03B9},         // ᾮ
+	0x1FAF: {0x1F67, 0x03B9},         // ᾯ
+	0x1FB2: {0x1F70, 0x03B9},         // ᾲ
+	0x1FB3: {0x03B1, 0x03B9},         // ᾳ
+	0x1FB4: {0x03AC, 0x03B9},         // ᾴ
+	0x1FB6: {0x03B1, 0x0342},         // ᾶ
+	0x1FB7: {0x03B1, 0x0342, 0x03B9}, // ᾷ
+	0x1FBC: {0x03B1, 0x03B9},         // ᾼ
+	0x1FC2: {0x1F74, 0x03B9},         // ῂ
+	0x1FC3: {0x03B7, 0x03B9},         // ῃ
+	0x1FC4: {0x03AE, 0x03B9},         // ῄ
+	0x1FC6: {0x03B7, 0x0342},         // ῆ
+	0x1FC7: {0x03B7, 0x0342, 0x03B9}, // ῇ
+	0x1FCC: {0x03B7, 0x03B9},         // ῌ
+	0x1FD2: {0x03B9, 0x0308, 0x0300}, // ῒ
+	0x1FD3: {0x03B9, 0x0308, 0x0301}, // ΐ
+	0x1FD6: {0x03B9, 0x0342},         // ῖ
+	0x1FD7: {0x03B9, 0x0308, 0x0342}, // ῗ
+	0x1FE2: {0x03C5, 0x0308, 0x0300}, // ῢ
+	0x1FE3: {0x03C5, 0x0308, 0x0301}, // ΰ
+	0x1FE4: {0x03C1, 0x0313},         // ῤ
+	0x1FE6: {0x03C5, 0x0342},         // ῦ
+	0x1FE7: {0x03C5, 0x0308, 0x0342}, // ῧ
+	0x1FF2: {0x1F7C, 0x03B9},         // ῲ
+	0x1FF3: {0x03C9, 0x03B9},         // ῳ
+	0x1FF4: {0x03CE, 0x03B9},         // ῴ
+	0x1FF6: {0x03C9, 0x0342},         // ῶ
+	0x1FF7: {0x03C9, 0x0342, 0x03B9}, // ῷ
+	0x1FFC: {0x03C9, 0x03B9},         // ῼ
+	0xFB00: {'f', 'f'},               // ﬀ
+	0xFB01: {'f', 'i'},               // ﬁ
+	0xFB02: {'f', 'l'},               // ﬂ
+	0xFB03: {'f', 'f', 'i'},          // ﬃ
+	0xFB04: {'f', 'f', 'l'},          // ﬄ
+	0xFB05: {'s', 't'},               // ﬅ
+	0xFB06: {'s', 't'},               // ﬆ
+	0xFB13: {0x0574, 0x0576},         // ﬓ
+	0xFB14: {0x0574, 0x0565},         // ﬔ
+	0xFB15: {0x0574, 0x056B},         // ﬕ
+	0xFB16: {0x057E, 0x0576},         // ﬖ
+	0xFB17: {0x0574, 0x056D},         // ﬗ
+}
+
+// FoldRune returns the case folding of r. Most runes fold to a single rune,
+// but some, like 'ß', fold to more then one ("ss").
+// The folding does not depend on the locale, so 'I' folds to 'i' and not 'ı'.
+func FoldRune(r rune) []rune {
+	if f, ok := fullFolds[r]; ok {
+		return f
+	}
+	return []rune{unicode.ToLower(unicode.ToUpper(r))}
+}
+
+// FoldString returns the case folding of s, two strings that are equal
+// ignoring case will have the same folding
+func FoldString(s string) string {
+	var str strings.Builder
+	str.Grow(len(s))
+	for _, r := range s {
+		for _, f := range FoldRune(r) {
+			str.WriteRune(f)
+		}
+	}
+	return str.String()
+}
+
+// Normalizer is a unicode normalization form;
+// the forms in golang.org/x/text/unicode/norm (e.g. norm.NFC) are Normalizers
+type Normalizer interface {
+	String(s string) string
+}
+
+// StringOptions controls how StringWith compares the input to the string
+type StringOptions struct {
+	// Fold will compare the strings using unicode case folding (see FoldString)
+	Fold bool
+	// Normalizer if not nil, will be used to normalize both the string and the input
+	// before comparing. So, with norm.NFC "é" will match "é".
+	Normalizer Normalizer
+}
+
+func (opts StringOptions) prepare(s string) string {
+	if opts.Normalizer != nil {
+		s = opts.Normalizer.String(s)
+	}
+	if opts.Fold {
+		s = FoldString(s)
+		if opts.Normalizer != nil {
+			s = opts.Normalizer.String(s)
+		}
+	}
+	return s
+}
+
+// isNonStarter approximates the runes that combine with the rune before them
+// in normalization; the combining marks
+func isNonStarter(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Mc, unicode.Me)
+}
+
+// StringWith matches the string, comparing it to the input as described by opts.
+// With a Normalizer the match will not end between a rune and the combining marks after it.
+// result is match
+func StringWith(match string, opts StringOptions) parse.Parser {
+	target := opts.prepare(match)
+	errVal := fmt.Errorf("unable to match %v", match)
+	return parse.Func(func(state parse.State) parse.State {
+		if target == "" {
+			return state.WithResult(match, state.Index)
+		}
+		var (
+			segment []rune
+			cstate  = state
+		)
+		for {
+			r, n, err := cstate.ReadNextRune()
+			if err != nil {
+				return state.WithError(readError(err, errVal))
+			}
+			cstate.Index += int64(n)
+			segment = append(segment, r)
+
+			if opts.Normalizer != nil {
+				// don't split a rune from it's combining marks
+				if next, _, err := cstate.ReadNextRune(); err == nil && isNonStarter(next) {
+					continue
+				}
+			}
+
+			got := opts.prepare(string(segment))
+			if got == target {
+				return state.WithResult(match, cstate.Index)
+			}
+			if !strings.HasPrefix(target, got) {
+				return state.WithError(errVal)
+			}
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/gdey/ppc/parse"
+)
+
+func TestStringInsensitive(t *testing.T) {
+	tests := []struct {
+		match string
+		input string
+		index int64
+		err   bool
+	}{
+		{match: "hello", input: "HeLLo world", index: 5},
+		{match: "straße", input: "STRASSE", index: 7},
+		{match: "STRASSE", input: "straße", index: 7},
+		{match: "ﬁle", input: "FILE", index: 4},
+		{match: "ᾳ", input: "αι", index: 4},
+		{match: "αι", input: "ᾼ", index: 3},
+		{match: "ᾀ", input: "ᾈ", index: 3},
+		{match: "ΐ", input: "\u03b9\u0308\u0301", index: 6},
+		{match: "ı", input: "I", err: true},
+		{match: "abc", input: "abd", err: true},
+	}
+	for _, tc := range tests {
+		state := parse.String(StringInsensitive(tc.match), tc.input)
+		if tc.err {
+			if !state.IsError {
+				t.Errorf("%q on %q: expected error, got %v", tc.match, tc.input, state.Index)
+			}
+			continue
+		}
+		if state.IsError {
+			t.Errorf("%q on %q: unexpected error %v", tc.match, tc.input, state.Err)
+			continue
+		}
+		if state.Index != tc.index {
+			t.Errorf("%q on %q: expected index %v got %v", tc.match, tc.input, tc.index, state.Index)
+		}
+	}
+}
+
+func TestFoldRune(t *testing.T) {
+	for r, folded := range fullFolds {
+		if r == 0x0131 {
+			continue
+		}
+		if len(folded) < 2 {
+			t.Errorf("%U: expected a full folding to more than one rune, got %U", r, folded)
+		}
+	}
+	if got := FoldString("\u1fb2 \u03a3\u0391\u03a3"); got != "\u1f70\u03b9 \u03c3\u03b1\u03c3" {
+		t.Errorf("FoldString, got %q", got)
+	}
+}
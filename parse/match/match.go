@@ -7,6 +7,7 @@ import (
 	"unicode"
 
 	"github.com/gdey/ppc/parse"
+	"github.com/gdey/ppc/parse/grapheme"
 )
 
 // AnyRune will match one rune
@@ -14,6 +15,43 @@ func AnyRune() parse.Parser {
 	return Rune(func(_ rune) bool { return true }, errors.New("unable to match letter"))
 }
 
+// Grapheme matches one extended grapheme cluster; what a reader would see as one character
+// e.g. "é" written as 'e' followed by a combining accent, or a flag emoji.
+// result is a string
+func Grapheme() parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		var (
+			seg    grapheme.Segmenter
+			runes  []rune
+			cstate = state
+		)
+		for {
+			r, n, err := cstate.ReadNextRune()
+			if parse.IsInvalidUTF8(err) {
+				return state.WithError(err)
+			}
+			if err != nil || seg.Break(r) {
+				break
+			}
+			runes = append(runes, r)
+			cstate.Index += int64(n)
+		}
+		if len(runes) == 0 {
+			return state.WithError(errors.New("unable to match grapheme"))
+		}
+		return state.WithResult(parse.RunesToString(runes), cstate.Index)
+	})
+}
+
+// readError is the error to use when a rune could not be read, or did not match.
+// Invalid UTF-8 errors are kept, so they are reported at the invalid byte.
+func readError(err error, errVal error) error {
+	if parse.IsInvalidUTF8(err) {
+		return err
+	}
+	return errVal
+}
+
 // Digit matches one unicode digit
 // result is a rune
 func Digit() parse.Parser {
@@ -22,7 +60,7 @@ func Digit() parse.Parser {
 		r, n, err := state.ReadNextRune()
 		if err != nil || !unicode.IsDigit(r) {
 			return state.WithError(
-				errors.New("unable to match letter"),
+				readError(err, errors.New("unable to match letter")),
 			)
 		}
 		return state.WithResult(r, state.Index+int64(n))
@@ -59,7 +97,7 @@ func Rune(fn func(rune) bool, errVal error) parse.Parser {
 
 		r, n, err := state.ReadNextRune()
 		if err != nil || !fn(r) {
-			return state.WithError(errVal)
+			return state.WithError(readError(err, errVal))
 		}
 		return state.WithResult(r, state.Index+int64(n))
 	})
@@ -75,7 +113,7 @@ func RuneN(n int) parse.Parser {
 		for i := 0; i < n; i++ {
 			r, nn, err := cstate.ReadNextRune()
 			if err != nil {
-				return state.WithError(fmt.Errorf("unable to match %v runes", n))
+				return state.WithError(readError(err, fmt.Errorf("unable to match %v runes", n)))
 			}
 			results[i] = r
 			cstate.Index += int64(nn)
@@ -98,6 +136,9 @@ func Runes(fn func(rune) bool, errVal error) parse.Parser {
 		for {
 
 			r, n, err := cstate.ReadNextRune()
+			if parse.IsInvalidUTF8(err) {
+				return state.WithError(err)
+			}
 			if err != nil || !fn(r) {
 
 				if len(runesRead) >= 1 {
@@ -116,6 +157,56 @@ func Runes(fn func(rune) bool, errVal error) parse.Parser {
 	})
 }
 
+// RunesMinMax matches at least min and at most max runes described by the provided function
+// A max less then zero means there is no upper limit
+// results in an array of runes
+func RunesMinMax(min, max int, fn func(rune) bool, errVal error) parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		var (
+			runesRead []rune
+			// Make a copy of the state, that we will modify.
+			cstate = state
+		)
+
+		for max < 0 || len(runesRead) < max {
+			r, n, err := cstate.ReadNextRune()
+			if parse.IsInvalidUTF8(err) {
+				return state.WithError(err)
+			}
+			if err != nil || !fn(r) {
+				break
+			}
+			runesRead = append(runesRead, r)
+			cstate.Index += int64(n)
+		}
+		if len(runesRead) < min {
+			return state.WithError(errVal)
+		}
+		return state.WithResult(
+			runesRead,
+			cstate.Index,
+		)
+	})
+}
+
+// Newline matches one line ending; "\r\n", "\n", "\r", or one of the
+// unicode line separators (see parse.IsNewline)
+// result is the line ending as a string
+func Newline() parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		r, n, err := state.ReadNextRune()
+		if err != nil || !parse.IsNewline(r) {
+			return state.WithError(readError(err, errors.New("unable to match a newline")))
+		}
+		if r == '\r' {
+			if next, _, err := state.ReadNextBytes(2); err == nil && next[1] == '\n' {
+				return state.WithResult("\r\n", state.Index+2)
+			}
+		}
+		return state.WithResult(string(r), state.Index+int64(n))
+	})
+}
+
 // Space matches one space
 func Space() parse.Parser {
 	return Rune(unicode.IsSpace, errors.New("unable to match a space"))
@@ -147,27 +238,21 @@ func String(match string) parse.Parser {
 }
 
 // StringInsensitive matches a string insensitive to the casing
+// Unicode case folding is used so "STRASSE" will match "straße"
 func StringInsensitive(match string) parse.Parser {
-	matchBytes := bytes.ToUpper([]byte(match))
-	return parse.Func(func(state parse.State) parse.State {
-
-		buff, n, err := state.ReadNextBytes(len(matchBytes))
+	return StringWith(match, StringOptions{Fold: true})
+}
 
-		if err != nil {
-			return state.WithError(
-				fmt.Errorf("unable to match %v", match),
-			)
-		}
-		if !bytes.HasPrefix(matchBytes, bytes.ToUpper(buff)) {
-			return state.WithError(
-				fmt.Errorf("unable to match %v", match),
-			)
+// Except will match p only if q does not match at the same index.
+// e.g. Except(AnyRune(), Space()) will match any rune but a space
+// result is the result of p
+func Except(p, q parse.Parser) parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		next := parse.Not(q).Run(state)
+		if next.IsError {
+			return next
 		}
-		return state.WithResult(
-			match,
-			state.Index+int64(n),
-		)
-
+		return p.Run(state)
 	})
 }
 
@@ -0,0 +1,52 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/gdey/ppc/parse"
+)
+
+func TestNewline(t *testing.T) {
+	tests := map[string]struct {
+		input  string
+		result string
+		index  int64
+		err    bool
+	}{
+		"lf":             {input: "\nx", result: "\n", index: 1},
+		"crlf":           {input: "\r\nx", result: "\r\n", index: 2},
+		"crlf at end":    {input: "\r\n", result: "\r\n", index: 2},
+		"cr":             {input: "\rx", result: "\r", index: 1},
+		"cr at end":      {input: "\r", result: "\r", index: 1},
+		"cr cr":          {input: "\r\r\n", result: "\r", index: 1},
+		"lf cr":          {input: "\n\r", result: "\n", index: 1},
+		"nel":            {input: "\u0085x", result: "\u0085", index: 2},
+		"line separator": {input: "\u2028x", result: "\u2028", index: 3},
+		"para separator": {input: "\u2029x", result: "\u2029", index: 3},
+		"not a newline":  {input: "x\n", err: true},
+		"empty":          {input: "", err: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.String(Newline(), tc.input)
+			if tc.err {
+				if !state.IsError {
+					t.Fatalf("expected error, got %q", state.Result)
+				}
+				return
+			}
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			if state.Result != tc.result || state.Index != tc.index {
+				t.Errorf("expected %q at %v got %q at %v", tc.result, tc.index, state.Result, state.Index)
+			}
+		})
+	}
+
+	// a mix of line endings are each one line
+	state := parse.String(parse.Many(parse.SequenceOf(Letters(), Newline())), "a\r\nb\rc\nd\u2028e\r\n")
+	if lines, _ := state.Result.([]interface{}); len(lines) != 5 || state.Index != 14 {
+		t.Errorf("expected 5 lines to 14, got %v lines to %v", len(lines), state.Index)
+	}
+}
@@ -0,0 +1,335 @@
+package match
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/gdey/ppc/parse"
+)
+
+// NumberError is returned when a numeric literal was matched, but could not be
+// converted to the requested type, (e.g. it overflows an int64)
+type NumberError struct {
+	Literal string
+	// Start and End are the indexes of the literal in the source
+	Start int64
+	End   int64
+	Err   error
+}
+
+func (err NumberError) Error() string {
+	return fmt.Sprintf("number literal %q at %v-%v: %v", err.Literal, err.Start, err.End, err.Err)
+}
+
+func (err NumberError) Unwrap() error { return err.Err }
+
+// ErrOverflow is wrapped by NumberError when a literal does not fit in the requested type
+var ErrOverflow = errors.New("value out of range")
+
+// number kinds that can be scanned
+const (
+	numDecimal = 1 << iota
+	numHex
+	numOctal
+	numBinary
+	numFloat
+
+	numAnyInteger = numDecimal | numHex | numOctal | numBinary
+)
+
+// numLiteral is a scanned number
+type numLiteral struct {
+	text    string // as it was in the source
+	digits  string // sign, digits, '.', and exponent; without the base prefix or underscores
+	base    int
+	isFloat bool
+	start   int64
+	end     int64
+}
+
+func (lit numLiteral) errorf(err error) NumberError {
+	return NumberError{
+		Literal: lit.text,
+		Start:   lit.start,
+		End:     lit.end,
+		Err:     err,
+	}
+}
+
+// numScanner reads runes from the source, one at a time
+type numScanner struct {
+	state parse.State
+	text  strings.Builder
+	clean strings.Builder
+}
+
+func (s *numScanner) peek() rune {
+	r, _, err := s.state.ReadNextRune()
+	if err != nil {
+		return -1
+	}
+	return r
+}
+
+// take consumes the next rune, keep will add it to the digits
+func (s *numScanner) take(keep bool) rune {
+	r, n, err := s.state.ReadNextRune()
+	if err != nil {
+		return -1
+	}
+	s.state.Index += int64(n)
+	s.text.WriteRune(r)
+	if keep {
+		s.clean.WriteRune(r)
+	}
+	return r
+}
+
+// digits consumes digits and underscores, underscores must be between digits,
+// or directly after a base prefix if afterPrefix is true
+func (s *numScanner) digits(isDigit func(rune) bool, afterPrefix bool) (count int, err error) {
+	lastUnderscore := false
+	for {
+		r := s.peek()
+		switch {
+		case r == '_':
+			if lastUnderscore || (count == 0 && !afterPrefix) {
+				return count, errors.New("'_' must separate successive digits")
+			}
+			s.take(false)
+			lastUnderscore = true
+		case r >= 0 && isDigit(r):
+			s.take(true)
+			count++
+			lastUnderscore = false
+		default:
+			if lastUnderscore {
+				return count, errors.New("'_' must separate successive digits")
+			}
+			return count, nil
+		}
+	}
+}
+
+func isDecimalDigit(r rune) bool { return '0' <= r && r <= '9' }
+func isOctalDigit(r rune) bool   { return '0' <= r && r <= '7' }
+func isBinaryDigit(r rune) bool  { return r == '0' || r == '1' }
+func isHexDigit(r rune) bool {
+	return isDecimalDigit(r) || ('a' <= r && r <= 'f') || ('A' <= r && r <= 'F')
+}
+
+// scanNumber will scan a number literal of the given kinds, the literal may have a leading sign.
+// Literals follow the go syntax: 0x, 0o, 0b prefixes; '_' between digits;
+// floats are decimal with a fraction and/or exponent. A '.' must be followed by a digit.
+func scanNumber(state parse.State, kinds int) (numLiteral, parse.State, error) {
+	s := numScanner{state: state}
+	lit := numLiteral{base: 10, start: state.Index}
+
+	if r := s.peek(); r == '+' || r == '-' {
+		s.take(r == '-')
+	}
+
+	if s.peek() == '0' && kinds&(numHex|numOctal|numBinary) != 0 {
+		prefixState := s.state
+		prefixState.Index++
+		pr, _, err := prefixState.ReadNextRune()
+		if err == nil {
+			var (
+				base    int
+				isDigit func(rune) bool
+			)
+			switch {
+			case kinds&numHex != 0 && (pr == 'x' || pr == 'X'):
+				base, isDigit = 16, isHexDigit
+			case kinds&numOctal != 0 && (pr == 'o' || pr == 'O'):
+				base, isDigit = 8, isOctalDigit
+			case kinds&numBinary != 0 && (pr == 'b' || pr == 'B'):
+				base, isDigit = 2, isBinaryDigit
+			}
+			if base != 0 {
+				s.take(false)
+				s.take(false)
+				lit.base = base
+				count, err := s.digits(isDigit, true)
+				if err == nil && count == 0 {
+					err = fmt.Errorf("expected base %v digits", base)
+				}
+				if r := s.peek(); err == nil && isDecimalDigit(r) {
+					// "0b102" is not 0b10 followed by a 2
+					err = fmt.Errorf("invalid digit %q in base %v literal", r, base)
+				}
+				if err != nil {
+					return lit, state, err
+				}
+				return s.finish(lit), s.state, nil
+			}
+		}
+	}
+
+	if kinds&(numDecimal|numFloat) == 0 {
+		return lit, state, errors.New("expected number prefix")
+	}
+
+	count, err := s.digits(isDecimalDigit, false)
+	if err != nil {
+		return lit, state, err
+	}
+	if count == 0 {
+		return lit, state, errors.New("expected digits")
+	}
+	if kinds&numFloat != 0 {
+		// fraction
+		if s.peek() == '.' {
+			next := s.state
+			next.Index++
+			if r, _, err := next.ReadNextRune(); err == nil && isDecimalDigit(r) {
+				s.take(true)
+				if _, err := s.digits(isDecimalDigit, false); err != nil {
+					return lit, state, err
+				}
+				lit.isFloat = true
+			}
+		}
+		// exponent
+		if r := s.peek(); r == 'e' || r == 'E' {
+			// only consume the exponent if there are digits
+			look := s.state
+			look.Index++
+			if r, _, err := look.ReadNextRune(); err == nil && (r == '+' || r == '-') {
+				look.Index++
+			}
+			if r, _, err := look.ReadNextRune(); err == nil && isDecimalDigit(r) {
+				s.take(true)
+				if r := s.peek(); r == '+' || r == '-' {
+					s.take(true)
+				}
+				if _, err := s.digits(isDecimalDigit, false); err != nil {
+					return lit, state, err
+				}
+				lit.isFloat = true
+			}
+		}
+		if !lit.isFloat && kinds&numDecimal == 0 {
+			// Floats will take integers as well, and convert them
+			lit.isFloat = true
+		}
+	}
+	return s.finish(lit), s.state, nil
+}
+
+func (s *numScanner) finish(lit numLiteral) numLiteral {
+	lit.text = s.text.String()
+	lit.digits = s.clean.String()
+	lit.end = s.state.Index
+	return lit
+}
+
+func (lit numLiteral) int64() (int64, error) {
+	i, err := strconv.ParseInt(lit.digits, lit.base, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, lit.errorf(fmt.Errorf("%w: does not fit in int64", ErrOverflow))
+		}
+		return 0, lit.errorf(err)
+	}
+	return i, nil
+}
+
+func (lit numLiteral) float64() (float64, error) {
+	f, err := strconv.ParseFloat(lit.digits, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, lit.errorf(fmt.Errorf("%w: does not fit in float64", ErrOverflow))
+		}
+		return 0, lit.errorf(err)
+	}
+	return f, nil
+}
+
+// number will return the smallest type the literal fits in
+func (lit numLiteral) number() (interface{}, error) {
+	if lit.isFloat {
+		f, err := strconv.ParseFloat(lit.digits, 64)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, strconv.ErrRange) {
+			return nil, lit.errorf(err)
+		}
+		bf, _, err := big.ParseFloat(lit.digits, 10, 0, big.ToNearestEven)
+		if err != nil {
+			return nil, lit.errorf(err)
+		}
+		return bf, nil
+	}
+	if i, err := strconv.ParseInt(lit.digits, lit.base, 64); err == nil {
+		return i, nil
+	}
+	if u, err := strconv.ParseUint(lit.digits, lit.base, 64); err == nil {
+		return u, nil
+	}
+	bi, ok := new(big.Int).SetString(lit.digits, lit.base)
+	if !ok {
+		return nil, lit.errorf(errors.New("invalid integer"))
+	}
+	return bi, nil
+}
+
+func numberParser(kinds int, name string, convert func(numLiteral) (interface{}, error)) parse.Parser {
+	return parse.Func(func(state parse.State) parse.State {
+		lit, next, err := scanNumber(state, kinds)
+		if err != nil {
+			return state.WithError(fmt.Errorf("unable to match %v at %v: %v", name, state.Index, err))
+		}
+		v, err := convert(lit)
+		if err != nil {
+			return state.WithError(err)
+		}
+		return state.WithResult(v, next.Index)
+	})
+}
+
+func asInt64(lit numLiteral) (interface{}, error)   { return lit.int64() }
+func asFloat64(lit numLiteral) (interface{}, error) { return lit.float64() }
+
+// Integer matches a decimal integer with an optional sign, '_' may be used to separate digits (1_000)
+// result is int64; a NumberError is returned if the value does not fit
+func Integer() parse.Parser {
+	return numberParser(numDecimal, "integer", asInt64)
+}
+
+// Hex matches a hexadecimal integer with an optional sign, (0xFF, -0x_1f)
+// result is int64; a NumberError is returned if the value does not fit
+func Hex() parse.Parser {
+	return numberParser(numHex, "hex integer", asInt64)
+}
+
+// Octal matches an octal integer with an optional sign, (0o755)
+// result is int64; a NumberError is returned if the value does not fit
+func Octal() parse.Parser {
+	return numberParser(numOctal, "octal integer", asInt64)
+}
+
+// Binary matches a binary integer with an optional sign, (0b1010_0101)
+// result is int64; a NumberError is returned if the value does not fit
+func Binary() parse.Parser {
+	return numberParser(numBinary, "binary integer", asInt64)
+}
+
+// Float matches a decimal number with an optional sign, fraction and exponent (-1.5e-3, 2, 1_000.5)
+// result is float64; a NumberError is returned if the value does not fit
+func Float() parse.Parser {
+	return numberParser(numFloat, "float", asFloat64)
+}
+
+// Number matches any of the number literals: decimal, hex, octal, and binary integers, or decimal floats.
+// A base prefix without any digits after it ("0x"), or followed by a digit that is not valid
+// for the base ("0b102"), fails rather than matching part of the literal.
+// result is the first of int64, uint64, or *big.Int that the integer fits in;
+// or for floats (a literal with a fraction or exponent), float64 or *big.Float if it does not fit
+func Number() parse.Parser {
+	return numberParser(numAnyInteger|numFloat, "number", numLiteral.number)
+}
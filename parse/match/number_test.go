@@ -0,0 +1,127 @@
+package match
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/gdey/ppc/parse"
+)
+
+func TestNumbers(t *testing.T) {
+	bigInt := func(s string) *big.Int {
+		i, _ := new(big.Int).SetString(s, 10)
+		return i
+	}
+	type tcase struct {
+		parser parse.Parser
+		input  string
+		result interface{}
+		index  int64
+		err    bool
+	}
+	tests := map[string]tcase{
+		"integer":                  {parser: Integer(), input: "123abc", result: int64(123), index: 3},
+		"integer underscores":      {parser: Integer(), input: "1_000_000", result: int64(1000000), index: 9},
+		"integer leading _":        {parser: Integer(), input: "_1", err: true},
+		"integer double _":         {parser: Integer(), input: "1__0", err: true},
+		"integer trailing _":       {parser: Integer(), input: "10_", err: true},
+		"integer plus":             {parser: Integer(), input: "+42", result: int64(42), index: 3},
+		"integer minus":            {parser: Integer(), input: "-42", result: int64(-42), index: 3},
+		"integer sign only":        {parser: Integer(), input: "-", err: true},
+		"integer min int64":        {parser: Integer(), input: "-9223372036854775808", result: int64(-9223372036854775808), index: 20},
+		"hex":                      {parser: Hex(), input: "0xFF", result: int64(255), index: 4},
+		"hex underscore after 0x":  {parser: Hex(), input: "0x_1f", result: int64(31), index: 5},
+		"hex negative":             {parser: Hex(), input: "-0X10", result: int64(-16), index: 5},
+		"hex no digits":            {parser: Hex(), input: "0x", err: true},
+		"hex no prefix":            {parser: Hex(), input: "ff", err: true},
+		"octal":                    {parser: Octal(), input: "0o755", result: int64(493), index: 5},
+		"octal bad digit":          {parser: Octal(), input: "0o8", err: true},
+		"binary":                   {parser: Binary(), input: "0b1010_0101", result: int64(165), index: 11},
+		"binary invalid digit":     {parser: Binary(), input: "0b102", err: true},
+		"octal invalid digit":      {parser: Octal(), input: "0o79", err: true},
+		"float":                    {parser: Float(), input: "-1.5e-3", result: -1.5e-3, index: 7},
+		"float integer":            {parser: Float(), input: "2", result: float64(2), index: 1},
+		"float underscores":        {parser: Float(), input: "1_000.5", result: 1000.5, index: 7},
+		"float dot without digits": {parser: Float(), input: "1.x", result: float64(1), index: 1},
+		"float e without digits":   {parser: Float(), input: "1ex", result: float64(1), index: 1},
+		"number int64":             {parser: Number(), input: "9223372036854775807", result: int64(9223372036854775807), index: 19},
+		"number uint64":            {parser: Number(), input: "9223372036854775808", result: uint64(9223372036854775808), index: 19},
+		"number big.Int":           {parser: Number(), input: "18446744073709551616", result: bigInt("18446744073709551616"), index: 20},
+		"number negative big.Int":  {parser: Number(), input: "-9223372036854775809", result: bigInt("-9223372036854775809"), index: 20},
+		"number hex uint64":        {parser: Number(), input: "0xFFFF_FFFF_FFFF_FFFF", result: uint64(1<<64 - 1), index: 21},
+		"number float":             {parser: Number(), input: "1.25", result: 1.25, index: 4},
+		"number exponent":          {parser: Number(), input: "1e3", result: float64(1000), index: 3},
+		"number bare 0x":           {parser: Number(), input: "0x", err: true},
+		"number bare 0b":           {parser: Number(), input: "0bz", err: true},
+		"number binary digit 2":    {parser: Number(), input: "0b102", err: true},
+		"number hex then letter":   {parser: Number(), input: "0x1fg", result: int64(31), index: 4},
+		"number 0o digits":         {parser: Number(), input: "0o17", result: int64(15), index: 4},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.String(tc.parser, tc.input)
+			if tc.err {
+				if !state.IsError {
+					t.Fatalf("expected error, got %#v at %v", state.Result, state.Index)
+				}
+				return
+			}
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			if state.Index != tc.index {
+				t.Errorf("index, expected %v got %v", tc.index, state.Index)
+			}
+			if bi, ok := tc.result.(*big.Int); ok {
+				got, ok := state.Result.(*big.Int)
+				if !ok || got.Cmp(bi) != 0 {
+					t.Errorf("result, expected %v got %#v", bi, state.Result)
+				}
+				return
+			}
+			if !reflect.DeepEqual(state.Result, tc.result) {
+				t.Errorf("result, expected %#v got %#v", tc.result, state.Result)
+			}
+		})
+	}
+}
+
+func TestNumberOverflow(t *testing.T) {
+	tests := map[string]struct {
+		parser parse.Parser
+		input  string
+		start  int64
+		end    int64
+	}{
+		"integer": {parser: Integer(), input: "9223372036854775808", start: 0, end: 19},
+		"hex":     {parser: Hex(), input: "-0x8000_0000_0000_0001", start: 0, end: 22},
+		"float":   {parser: Float(), input: "1e400", start: 0, end: 5},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.String(tc.parser, tc.input)
+			if !state.IsError {
+				t.Fatalf("expected error, got %#v", state.Result)
+			}
+			var nerr NumberError
+			if !errors.As(state.Err, &nerr) {
+				t.Fatalf("expected NumberError, got %T: %v", state.Err, state.Err)
+			}
+			if !errors.Is(nerr, ErrOverflow) {
+				t.Errorf("expected ErrOverflow, got %v", nerr.Err)
+			}
+			if nerr.Start != tc.start || nerr.End != tc.end || nerr.Literal != tc.input {
+				t.Errorf("expected %q at %v-%v, got %q at %v-%v", tc.input, tc.start, tc.end, nerr.Literal, nerr.Start, nerr.End)
+			}
+		})
+	}
+
+	// the span is of the literal, not the start of input
+	state := parse.String(parse.SequenceOf(String("x = "), Integer()), "x = 99999999999999999999")
+	var nerr NumberError
+	if !errors.As(state.Err, &nerr) || nerr.Start != 4 || nerr.End != 24 {
+		t.Errorf("expected NumberError at 4-24, got %v", state.Err)
+	}
+}
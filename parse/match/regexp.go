@@ -0,0 +1,113 @@
+package match
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/gdey/ppc/parse"
+)
+
+// DefaultRegexpWindow is the number of bytes Regexp will allow the regular
+// expression to look at past the current index.
+const DefaultRegexpWindow = 64 * 1024
+
+// ErrRegexpWindow is wrapped by the error of Regexp and RegexpWindow when the regular expression
+// had to look past the end of its window, and there is more input after the window.
+var ErrRegexpWindow = errors.New("regular expression needs more than its window")
+
+// Regexp matches the regular expression at the current index.
+// The pattern is anchored at the current index, so it will not
+// skip any input looking for a match.
+//
+// A regular expression may read past the end of what it matches; (e.g. `a.*?b` will
+// read till it finds a `b`) so the expression is only allowed to see DefaultRegexpWindow bytes.
+// If the expression reads up to the end of the window (the regexp package reads a rune or two
+// past the end of a match) and there is more input after it, the result could be different with
+// the rest of the input: `[a-z]+` would stop at the window, `abc$` would see the end of the
+// window as the end of the input. So the parser fails with an error wrapping ErrRegexpWindow.
+// For expressions that are known to be short, (identifiers, numbers, dates) use RegexpWindow with
+// a small window, to avoid the reading of input that will never be matched; for expressions
+// that need to see more then the default use RegexpWindow with a larger window or -1 for no limit.
+//
+// Regexp will panic if the pattern does not compile.
+// result is []string where the first element is the whole match, followed by the submatches.
+// Submatches that did not participate in the match are "".
+func Regexp(pattern string) parse.Parser {
+	return RegexpWindow(pattern, DefaultRegexpWindow)
+}
+
+// RegexpWindow is like Regexp, but the regular expression will only be allowed to see window
+// bytes past the current index. A negative window means there is no limit.
+// The parser fails with ErrRegexpWindow if the expression needs to see more than the window.
+func RegexpWindow(pattern string, window int64) parse.Parser {
+	re := regexp.MustCompile(`\A(?:` + pattern + `)`)
+	if window < 0 {
+		window = math.MaxInt64
+	}
+	return parse.Func(func(state parse.State) parse.State {
+		if state.Source == nil {
+			return state.WithError(parse.ErrNoSource)
+		}
+		size := window
+		if size > math.MaxInt64-state.Index {
+			size = math.MaxInt64 - state.Index
+		}
+		reader := &windowReader{
+			reader: bufio.NewReader(io.NewSectionReader(state.Source, state.Index, size)),
+			size:   size,
+		}
+
+		loc := re.FindReaderSubmatchIndex(reader)
+		if reader.atEdge && size < math.MaxInt64-state.Index {
+			// only an error if there is input after the window
+			var b [1]byte
+			if n, _ := state.Source.ReadAt(b[:], state.Index+size); n == 1 {
+				return state.WithError(fmt.Errorf("unable to match /%v/ at %v: %w of %v bytes", pattern, state.Index, ErrRegexpWindow, size))
+			}
+		}
+		if loc == nil {
+			return state.WithError(fmt.Errorf("unable to match /%v/ at %v", pattern, state.Index))
+		}
+
+		// Read back the matched text so we can slice out the submatches.
+		buff := make([]byte, loc[1])
+		n, err := state.Source.ReadAt(buff, state.Index)
+		if n != len(buff) {
+			return state.WithError(fmt.Errorf("unable to read match of /%v/ at %v: %v", pattern, state.Index, err))
+		}
+		results := make([]string, len(loc)/2)
+		for i := range results {
+			if loc[2*i] < 0 {
+				continue
+			}
+			results[i] = string(buff[loc[2*i]:loc[2*i+1]])
+		}
+		return state.WithResult(results, state.Index+int64(loc[1]))
+	})
+}
+
+// windowReader records if the regular expression read up to the end of the window
+type windowReader struct {
+	reader *bufio.Reader
+	offset int64
+	size   int64
+	atEdge bool
+}
+
+func (w *windowReader) ReadRune() (rune, int, error) {
+	r, n, err := w.reader.ReadRune()
+	switch {
+	case err == io.EOF:
+		w.atEdge = true
+	case r == utf8.RuneError && n == 1 && w.size-w.offset < utf8.UTFMax:
+		// a rune may have been cut in two by the end of the window
+		w.atEdge = true
+	}
+	w.offset += int64(n)
+	return r, n, err
+}
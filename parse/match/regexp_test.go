@@ -0,0 +1,66 @@
+package match
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gdey/ppc/parse"
+)
+
+func TestRegexp(t *testing.T) {
+	tests := map[string]struct {
+		parser parse.Parser
+		input  string
+		result []string
+		index  int64
+		err    error
+	}{
+		"whole match":      {parser: Regexp(`[0-9]+`), input: "123abc", result: []string{"123"}, index: 3},
+		"anchored":         {parser: Regexp(`[0-9]+`), input: "abc123", err: errAny},
+		"at the index":     {parser: parse.Map(parse.SequenceOf(String("x"), Regexp(`[0-9]+`)), second), input: "x12y", result: []string{"12"}, index: 3},
+		"alternation":      {parser: Regexp(`b|ab`), input: "abc", result: []string{"ab"}, index: 2},
+		"submatches":       {parser: Regexp(`(\w+)@(\w+)(\.com)?`), input: "me@example.org", result: []string{"me@example", "me", "example", ""}, index: 10},
+		"empty match":      {parser: Regexp(`a*`), input: "bbb", result: []string{""}, index: 0},
+		"no limit":         {parser: RegexpWindow(`a.*?b`, -1), input: "a----b", result: []string{"a----b"}, index: 6},
+		"fits the window":  {parser: RegexpWindow(`[a-z]+\b`, 6), input: "ab cdefgh", result: []string{"ab"}, index: 2},
+		"ends at the end":  {parser: RegexpWindow(`[a-z]+\b`, 4), input: "abcd", result: []string{"abcd"}, index: 4},
+		"word overflow":    {parser: RegexpWindow(`[a-z]+\b`, 4), input: "abcdefgh", err: ErrRegexpWindow},
+		"end of text":      {parser: RegexpWindow(`abc$`, 3), input: "abcdef", err: ErrRegexpWindow},
+		"lookahead":        {parser: RegexpWindow(`abcd|a`, 3), input: "abcd", err: ErrRegexpWindow},
+		"no match in view": {parser: RegexpWindow(`a.*?b`, 4), input: "a-----b", err: ErrRegexpWindow},
+		"split rune":       {parser: RegexpWindow(`\pL+`, 4), input: "abcé", err: ErrRegexpWindow},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.String(tc.parser, tc.input)
+			if tc.err != nil {
+				if !state.IsError {
+					t.Fatalf("expected error, got %q at %v", state.Result, state.Index)
+				}
+				if tc.err != errAny && !errors.Is(state.Err, tc.err) {
+					t.Errorf("expected %v, got %v", tc.err, state.Err)
+				}
+				return
+			}
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			if !reflect.DeepEqual(state.Result, tc.result) || state.Index != tc.index {
+				t.Errorf("expected %q at %v got %q at %v", tc.result, tc.index, state.Result, state.Index)
+			}
+		})
+	}
+}
+
+func TestRegexpStream(t *testing.T) {
+	state := parse.Items(Regexp(`a`), parse.Slice{"a"})
+	if state.Err != parse.ErrNoSource {
+		t.Errorf("expected ErrNoSource got %v", state.Err)
+	}
+}
+
+// errAny is for the test cases that only expect an error
+var errAny = errors.New("any error")
+
+func second(r interface{}) interface{} { return r.([]interface{})[1] }
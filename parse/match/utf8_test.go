@@ -0,0 +1,44 @@
+package match
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gdey/ppc/parse"
+)
+
+func TestInvalidUTF8(t *testing.T) {
+	run := func(parser parse.Parser, input string, policy parse.UTF8Policy) parse.State {
+		return parser.Run(parse.State{Source: strings.NewReader(input), UTF8: policy})
+	}
+
+	// UTF8Raw keeps the invalid bytes in the result
+	state := run(Grapheme(), "\xFFa", parse.UTF8Raw)
+	if state.IsError || state.Result != "\xFF" || state.Index != 1 {
+		t.Errorf("grapheme raw, expected %q at 1 got %q at %v (%v)", "\xFF", state.Result, state.Index, state.Err)
+	}
+
+	// UTF8Error is reported at the invalid byte
+	tests := map[string]struct {
+		parser parse.Parser
+		input  string
+		offset int64
+	}{
+		"grapheme":           {parser: Grapheme(), input: "\xFF", offset: 0},
+		"string insensitive": {parser: StringInsensitive("abc"), input: "AB\xFF", offset: 2},
+		"string with":        {parser: StringWith("abc", StringOptions{}), input: "ab\xFF", offset: 2},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := run(tc.parser, tc.input, parse.UTF8Error)
+			var invalid *parse.InvalidUTF8Error
+			if !errors.As(state.Err, &invalid) {
+				t.Fatalf("expected an invalid UTF-8 error got %v", state.Err)
+			}
+			if invalid.Offset != tc.offset {
+				t.Errorf("offset, expected %v got %v", tc.offset, invalid.Offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package match
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gdey/ppc/parse"
+)
+
+// trie is a prefix tree of runes
+type trie struct {
+	children map[rune]*trie
+	// word is the word as given, if a word ends at this node
+	word     string
+	terminal bool
+}
+
+func (t *trie) insert(word string, fold func(rune) []rune) {
+	node := t
+	for _, wr := range word {
+		for _, r := range fold(wr) {
+			next, ok := node.children[r]
+			if !ok {
+				if node.children == nil {
+					node.children = make(map[rune]*trie)
+				}
+				next = new(trie)
+				node.children[r] = next
+			}
+			node = next
+		}
+	}
+	if !node.terminal {
+		node.terminal = true
+		node.word = word
+	}
+}
+
+func noFold(r rune) []rune { return []rune{r} }
+
+// IsWordRune reports whether r is part of a word; a letter, digit, or '_'
+func IsWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// OneOf matches the longest of the given words, the order of the words does not matter.
+// result is the word that matched, as given
+func OneOf(words ...string) parse.Parser {
+	return Words(false, nil, words...)
+}
+
+// Keywords matches the longest of the given words, that is not followed by a word rune (see IsWordRune)
+// So, Keywords(true, "select") will match "SELECT *" but not "selection".
+// If caseFold is true, the words are matched insensitive to case (see FoldString).
+// result is the word that matched, as given
+func Keywords(caseFold bool, words ...string) parse.Parser {
+	return Words(caseFold, IsWordRune, words...)
+}
+
+// Words matches the longest of the given words. If caseFold is true, the words
+// are matched insensitive to case. If inWord is not nil, a word will only
+// match if the rune after it is not an inWord rune, or is the end of input.
+// The words are stored in a trie, so the input is only read once no matter how
+// many words are given.
+// result is the word that matched, as given
+func Words(caseFold bool, inWord func(rune) bool, words ...string) parse.Parser {
+	fold := noFold
+	if caseFold {
+		fold = FoldRune
+	}
+	root := new(trie)
+	for _, word := range words {
+		root.insert(word, fold)
+	}
+	errVal := fmt.Errorf("unable to match one of %v", strings.Join(words, ", "))
+
+	return parse.Func(func(state parse.State) parse.State {
+		var (
+			node   = root
+			cstate = state
+			match  *trie
+			end    int64
+		)
+		for node != nil {
+			if node.terminal && node != root {
+				// check the boundary
+				ok := true
+				if inWord != nil {
+					r, _, err := cstate.ReadNextRune()
+					ok = err != nil || !inWord(r)
+				}
+				if ok {
+					match, end = node, cstate.Index
+				}
+			}
+			if len(node.children) == 0 {
+				break
+			}
+			r, n, err := cstate.ReadNextRune()
+			if err != nil {
+				break
+			}
+			for _, fr := range fold(r) {
+				if node = node.children[fr]; node == nil {
+					break
+				}
+			}
+			cstate.Index += int64(n)
+		}
+		if match == nil {
+			return state.WithError(errVal)
+		}
+		return state.WithResult(match.word, end)
+	})
+}
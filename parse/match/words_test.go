@@ -0,0 +1,65 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/gdey/ppc/parse"
+)
+
+func TestWords(t *testing.T) {
+	overlapping := []string{"selection", "sel", "select"}
+	tests := map[string]struct {
+		parser parse.Parser
+		input  string
+		result string
+		index  int64
+		err    bool
+	}{
+		"shortest":              {parser: OneOf(overlapping...), input: "sel", result: "sel", index: 3},
+		"middle":                {parser: OneOf(overlapping...), input: "select", result: "select", index: 6},
+		"longest":               {parser: OneOf(overlapping...), input: "selections", result: "selection", index: 9},
+		"between words":         {parser: OneOf(overlapping...), input: "selec", result: "sel", index: 3},
+		"after the middle":      {parser: OneOf(overlapping...), input: "selectio", result: "select", index: 6},
+		"no prefix":             {parser: OneOf(overlapping...), input: "se", err: true},
+		"keyword":               {parser: Keywords(false, overlapping...), input: "select *", result: "select", index: 6},
+		"keyword at the end":    {parser: Keywords(false, overlapping...), input: "sel", result: "sel", index: 3},
+		"keyword in identifier": {parser: Keywords(false, overlapping...), input: "selects", err: true},
+		"keyword underscore":    {parser: Keywords(false, overlapping...), input: "select_all", err: true},
+		"keyword digit":         {parser: Keywords(false, overlapping...), input: "sel2", err: true},
+		"keyword shorter fits":  {parser: Keywords(false, "if", "iffy"), input: "iff", err: true},
+		"keyword punctuation":   {parser: Keywords(false, overlapping...), input: "selection.x", result: "selection", index: 9},
+		"case sensitive":        {parser: Keywords(false, "select"), input: "SELECT", err: true},
+		"case fold":             {parser: Keywords(true, "select"), input: "SeLeCt", result: "select", index: 6},
+		"fold sharp s":          {parser: Keywords(true, "straße"), input: "STRASSE", result: "straße", index: 7},
+		"fold to sharp s":       {parser: Words(true, nil, "STRASSE"), input: "straße", result: "STRASSE", index: 7},
+		"fold then word rune":   {parser: Keywords(true, "straße"), input: "STRASSEN", err: true},
+		"fold prefix":           {parser: Words(true, nil, "strass", "straße"), input: "STRASSE", result: "straße", index: 7},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := parse.String(tc.parser, tc.input)
+			if tc.err {
+				if !state.IsError {
+					t.Fatalf("expected error, got %q at %v", state.Result, state.Index)
+				}
+				return
+			}
+			if state.IsError {
+				t.Fatalf("unexpected error: %v", state.Err)
+			}
+			if state.Result != tc.result || state.Index != tc.index {
+				t.Errorf("expected %q at %v got %q at %v", tc.result, tc.index, state.Result, state.Index)
+			}
+		})
+	}
+}
+
+func TestWordsError(t *testing.T) {
+	state := parse.String(OneOf("sel", "select"), "x")
+	if expected := "unable to match one of sel, select"; state.Err == nil || state.Err.Error() != expected {
+		t.Errorf("expected %q got %v", expected, state.Err)
+	}
+	if state.Index != 0 {
+		t.Errorf("expected the index to stay at 0, got %v", state.Index)
+	}
+}
@@ -0,0 +1,61 @@
+package parse
+
+import (
+	"unicode/utf8"
+)
+
+// IsNewline reports whether r ends a line; "\n", "\r", NEL (U+0085),
+// LINE SEPARATOR (U+2028), or PARAGRAPH SEPARATOR (U+2029).
+// "\r\n" is one line ending, made up of two newline runes.
+func IsNewline(r rune) bool {
+	switch r {
+	case '\n', '\r', 0x85, 0x2028, 0x2029:
+		return true
+	}
+	return false
+}
+
+// lastLine returns the number of line endings in b, and the index where the last line starts
+func lastLine(b []byte) (lines int, start int) {
+	for i := 0; i < len(b); {
+		r, n := utf8.DecodeRune(b[i:])
+		i += n
+		if !IsNewline(r) {
+			continue
+		}
+		if r == '\r' && i < len(b) && b[i] == '\n' {
+			i++
+		}
+		lines++
+		start = i
+	}
+	return lines, start
+}
+
+// afterNewline reports whether the bytes before the index end a line
+func (state State) afterNewline() bool {
+	if state.Index <= 0 {
+		return false
+	}
+	start := state.Index - utf8.UTFMax
+	if start < 0 {
+		start = 0
+	}
+	buff := make([]byte, state.Index-start)
+	n, _ := state.Source.ReadAt(buff, start)
+	if int64(n) != state.Index-start {
+		return false
+	}
+	r, _ := utf8.DecodeLastRune(buff)
+	if !IsNewline(r) {
+		return false
+	}
+	if r == '\r' {
+		// between a "\r" and "\n" is not the start of a line
+		next, _, err := state.ReadNextBytes(1)
+		if err == nil && next[0] == '\n' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineColumn(t *testing.T) {
+	tests := map[string]struct {
+		input  string
+		index  int64
+		line   int
+		column int
+		offset int
+	}{
+		"start":            {input: "ab", index: 0, line: 1, column: 1, offset: 0},
+		"lf":               {input: "a\nbc", index: 3, line: 2, column: 2, offset: 2},
+		"crlf":             {input: "a\r\nbc", index: 4, line: 2, column: 2, offset: 2},
+		"after crlf":       {input: "a\r\nbc", index: 3, line: 2, column: 1, offset: 1},
+		"between cr lf":    {input: "a\r\nbc", index: 2, line: 2, column: 1, offset: 1},
+		"cr":               {input: "a\rbc", index: 3, line: 2, column: 2, offset: 2},
+		"line separator":   {input: "a\u2028bc", index: 5, line: 2, column: 2, offset: 2},
+		"nel":              {input: "a\u0085b", index: 4, line: 2, column: 2, offset: 2},
+		"mixed":            {input: "a\nb\r\nc\rd\u2029e\r\n\nf", index: 16, line: 7, column: 2, offset: 2},
+		"cr cr lf":         {input: "a\r\r\nb", index: 5, line: 3, column: 2, offset: 2},
+		"tab after crlf":   {input: "a\r\n\tb", index: 5, line: 2, column: 10, offset: 3},
+		"wide after crlf":  {input: "a\r\n世b", index: 7, line: 2, column: 4, offset: 5},
+		"end of last line": {input: "a\r\nb", index: 4, line: 2, column: 2, offset: 2},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := State{Source: strings.NewReader(tc.input), Index: tc.index}
+			line, column, err := state.LineColumn(0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if line != tc.line || column != tc.column {
+				t.Errorf("line column, expected %v:%v got %v:%v", tc.line, tc.column, line, column)
+			}
+			// LineOffset counts lines from 0, and offsets in bytes from 1
+			line, offset, _ := state.LineOffset()
+			if line != tc.line-1 || offset != tc.offset {
+				t.Errorf("line offset, expected %v:%v got %v:%v", tc.line-1, tc.offset, line, offset)
+			}
+		})
+	}
+}
+
+func TestStartOfLine(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		index int64
+		start bool
+	}{
+		"start of input":  {input: "ab", index: 0, start: true},
+		"middle of line":  {input: "ab", index: 1},
+		"after lf":        {input: "a\nb", index: 2, start: true},
+		"after crlf":      {input: "a\r\nb", index: 3, start: true},
+		"between cr lf":   {input: "a\r\nb", index: 2},
+		"after cr":        {input: "a\rb", index: 2, start: true},
+		"after cr at end": {input: "a\r", index: 2, start: true},
+		"after u2028":     {input: "a\u2028b", index: 4, start: true},
+		"after u2029":     {input: "a\u2029b", index: 4, start: true},
+		"after nel":       {input: "a\u0085b", index: 3, start: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := State{Source: strings.NewReader(tc.input), Index: tc.index}
+			next := StartOfLine().Run(state)
+			if next.IsError == tc.start {
+				t.Errorf("expected start of line %v, got error %v", tc.start, next.Err)
+			}
+		})
+	}
+}
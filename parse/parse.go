@@ -5,10 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"strings"
 	"unicode/utf8"
+
+	"github.com/gdey/ppc/parse/grapheme"
 )
 
 type Parser interface {
@@ -28,66 +29,103 @@ type State struct {
 	Result interface{}
 	Index  int64
 	Source io.ReaderAt
+	// Stream if not nil, is parsed instead of Source, and Index is the
+	// position of the next item in the stream
+	Stream Stream
+	// UTF8 is how invalid UTF-8 in the Source is handled when reading runes
+	UTF8 UTF8Policy
+	// Bit is the offset of the next bit in the byte at Index, it is only
+	// used by the bit parsers in parse/binary, and zero otherwise
+	Bit uint8
 
 	IsError bool
 	Err     error
 }
 
 func (state State) WithResult(Result interface{}, Index int64) State {
-	return State{
-		Result: Result,
-		Index:  Index,
-		Source: state.Source,
-
-		IsError: state.IsError,
-		Err:     state.Err,
-	}
+	state.Result = Result
+	state.Index = Index
+	return state
 }
 
 func (state State) WithError(err error) State {
-	return State{
-		Result: state.Result,
-		Index:  state.Index,
-		Source: state.Source,
-
-		IsError: true,
-		Err:     err,
-	}
+	state.IsError = true
+	state.Err = err
+	return state
 }
 
-// LineOffset returns the line (as defined by "\n") and offset of the currect index
+// LineOffset returns the line and offset of the currect index
+// Lines end with any of the newlines, see IsNewline
 func (state State) LineOffset() (line int, offset int, err error) {
+	if state.Source == nil {
+		return 0, 0, ErrNoSource
+	}
 	var (
 		buff = make([]byte, state.Index)
 		n    int
 	)
 	n, err = state.Source.ReadAt(buff, 0)
 	if n > 0 {
-		line = bytes.Count(buff[:n], []byte("\n"))
-		offset = n - bytes.LastIndex(buff[:n], []byte("\n"))
+		var start int
+		line, start = lastLine(buff[:n])
+		offset = n - start + 1
 	}
 	return line, offset, err
 }
 
+// LineColumn returns the line and column of the current index, both starting at 1.
+// The column is in display cells, so it will line up with what is shown in a terminal;
+// a grapheme cluster counts as one cell, or two if it is wide, and tabs move to the
+// next multiple of tabWidth.
+func (state State) LineColumn(tabWidth int) (line int, column int, err error) {
+	if state.Source == nil {
+		return 0, 0, ErrNoSource
+	}
+	var (
+		buff = make([]byte, state.Index)
+		n    int
+	)
+	n, err = state.Source.ReadAt(buff, 0)
+	if err == io.EOF && int64(n) == state.Index {
+		err = nil
+	}
+	buff = buff[:n]
+	line, start := lastLine(buff)
+	line++
+	column = grapheme.Column(string(buff[start:]), tabWidth) + 1
+	return line, column, err
+}
+
 func (state State) ReadNextBytes(n int) ([]byte, int, error) {
+	if state.Source == nil {
+		return nil, 0, ErrNoSource
+	}
 	buff := make([]byte, n)
 	nn, err := state.Source.ReadAt(buff, state.Index)
 	return buff, nn, err
 }
 
+// ReadNextRune decodes the UTF-8 rune at the current index.
+// Bytes that are not valid UTF-8 are handled as described by the state's UTF8 policy.
 func (state State) ReadNextRune() (rune, int, error) {
-	var (
-		buff []byte
-		err  error
-	)
-	for byteCount, gotFullRune := 1, false; !gotFullRune; byteCount, gotFullRune = byteCount+1, utf8.FullRune(buff) {
-		buff, _, err = state.ReadNextBytes(byteCount)
-		if err != nil {
-			return 0, 0, err
+	buff, n, err := state.ReadNextBytes(utf8.UTFMax)
+	if n == 0 {
+		if err == nil {
+			err = io.EOF
+		}
+		return 0, 0, err
+	}
+	r, size := utf8.DecodeRune(buff[:n])
+	if r == utf8.RuneError && size <= 1 {
+		switch state.UTF8 {
+		case UTF8Error:
+			return 0, 0, &InvalidUTF8Error{Offset: state.Index, Byte: buff[0]}
+		case UTF8Raw:
+			return RawByteRune(buff[0]), 1, nil
 		}
+		return utf8.RuneError, 1, nil
 	}
-	r, n := utf8.DecodeRune(buff)
-	return r, n, nil
+	return r, size, nil
 }
 
 func (state State) ReadNextRunes(n int) ([]rune, int, error) {
@@ -124,7 +162,7 @@ func ApplyN(n int, parser Parser) Parser {
 			results = append(results, next.Result)
 		}
 
-		return state.WithResult(
+		return next.WithResult(
 			results,
 			next.Index,
 		)
@@ -171,12 +209,12 @@ func Chain(parser Parser, fn func(result interface{}) Parser) Parser {
 func ChoiceOf(parser1 Parser, rest ...Parser) Parser {
 	return Func(func(state State) State {
 		next := parser1.Run(state)
-		if !next.IsError {
+		if !next.IsError || IsInvalidUTF8(next.Err) {
 			return next
 		}
 		for _, p := range rest {
 			next = p.Run(state)
-			if !next.IsError {
+			if !next.IsError || IsInvalidUTF8(next.Err) {
 				return next
 			}
 
@@ -243,6 +281,9 @@ func Many(parser Parser) Parser {
 		for {
 			next = parser.Run(state)
 			if next.IsError {
+				if IsInvalidUTF8(next.Err) {
+					return next
+				}
 				break
 			}
 			results = append(results, next.Result)
@@ -266,6 +307,9 @@ func Many1(parser Parser) Parser {
 		for {
 			next = parser.Run(state)
 			if next.IsError {
+				if IsInvalidUTF8(next.Err) {
+					return next
+				}
 				break
 			}
 			results = append(results, next.Result)
@@ -286,7 +330,7 @@ func Many1(parser Parser) Parser {
 func Optional(parser Parser) Parser {
 	return Func(func(state State) State {
 		next := parser.Run(state)
-		if next.IsError {
+		if next.IsError && !IsInvalidUTF8(next.Err) {
 			return state
 		}
 		return next
@@ -307,6 +351,32 @@ func Peek(parser Parser) Parser {
 	})
 }
 
+// And is a positive lookahead. It will match if the parser matches, but will
+// not consume any input.
+// result is the result of parser
+func And(parser Parser) Parser {
+	return Func(func(state State) State {
+		next := parser.Run(state)
+		if next.IsError {
+			return state.WithError(next.Err)
+		}
+		return state.WithResult(next.Result, state.Index)
+	})
+}
+
+// Not is a negative lookahead. It will match if the parser does not match, and
+// will error if it does. No input is consumed.
+// result is nil
+func Not(parser Parser) Parser {
+	return Func(func(state State) State {
+		next := parser.Run(state)
+		if !next.IsError {
+			return state.WithError(fmt.Errorf("unexpected match at %v", state.Index))
+		}
+		return state.WithResult(nil, state.Index)
+	})
+}
+
 // SequenceOf will attempt to match each given parser in the order specified
 func SequenceOf(parser1 Parser, rest ...Parser) Parser {
 	return Func(func(state State) State {
@@ -327,7 +397,7 @@ func SequenceOf(parser1 Parser, rest ...Parser) Parser {
 			}
 			results = append(results, next.Result)
 		}
-		return state.WithResult(
+		return next.WithResult(
 			results,
 			next.Index,
 		)
@@ -358,7 +428,7 @@ func SequenceOfNoNil(parser1 Parser, rest ...Parser) Parser {
 			}
 			results = append(results, next.Result)
 		}
-		return state.WithResult(
+		return next.WithResult(
 			results,
 			next.Index,
 		)
@@ -374,12 +444,20 @@ func StartOfInput() Parser {
 		return state
 	})
 }
+
+// EndOfInput matches when there is nothing left to read at the index
 func EndOfInput() Parser {
 	return Func(func(state State) State {
+		if state.Stream != nil {
+			if state.Index < state.Stream.Len() {
+				return state.WithError(errors.New("expected end of input"))
+			}
+			return state
+		}
 		buff := make([]byte, 1)
-		//force read 1 byte passed to see if we get EOF
-		_, err := state.Source.ReadAt(buff, state.Index+1)
-		if err != io.EOF {
+		// if there is not a byte at the index we are at the end
+		n, err := state.Source.ReadAt(buff, state.Index)
+		if n != 0 || err != io.EOF {
 			return state.WithError(errors.New("expected end of input"))
 		}
 		return state
@@ -391,12 +469,12 @@ func StartOfLine() Parser {
 		if state.Index == 0 {
 			return state
 		}
+		if state.Source == nil {
+			return state.WithError(ErrNoSource)
+		}
 
-		// check to see if the previous index was -1
-		buff := make([]byte, 1)
-		_, err := state.Source.ReadAt(buff, state.Index-1)
-		log.Printf("buff: %c -- %v", buff[0], err)
-		if err != nil || buff[0] != '\n' {
+		// check to see if the previous rune ended a line
+		if !state.afterNewline() {
 			return state.WithError(errors.New("expected start of line"))
 		}
 
@@ -413,6 +491,13 @@ func String(parser Parser, s string) State {
 	return parser.Run(originalState)
 }
 
+func Bytes(parser Parser, b []byte) State {
+	originalState := State{
+		Source: bytes.NewReader(b),
+	}
+	return parser.Run(originalState)
+}
+
 func File(parser Parser, filename string) (State, error) {
 	f, err := os.Open(filename)
 	if err != nil {
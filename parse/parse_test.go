@@ -0,0 +1,50 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEndOfInput(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		index int64
+		err   bool
+	}{
+		"empty":          {input: "", index: 0},
+		"at end":         {input: "ab", index: 2},
+		"last byte left": {input: "ab", index: 1, err: true},
+		"at start":       {input: "ab", index: 0, err: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := State{Source: strings.NewReader(tc.input), Index: tc.index}
+			next := EndOfInput().Run(state)
+			if next.IsError != tc.err {
+				t.Fatalf("error, expected %v got %v", tc.err, next.Err)
+			}
+			if next.Index != tc.index {
+				t.Errorf("index, expected %v got %v", tc.index, next.Index)
+			}
+		})
+	}
+}
+
+func TestNoSource(t *testing.T) {
+	state := State{Stream: Slice{"a", "b"}, Index: 1}
+	if _, _, err := state.LineColumn(0); err != ErrNoSource {
+		t.Errorf("LineColumn, expected ErrNoSource got %v", err)
+	}
+	if _, _, err := state.LineOffset(); err != ErrNoSource {
+		t.Errorf("LineOffset, expected ErrNoSource got %v", err)
+	}
+	if _, _, err := state.ReadNextRune(); err != ErrNoSource {
+		t.Errorf("ReadNextRune, expected ErrNoSource got %v", err)
+	}
+	if next := StartOfLine().Run(state); next.Err != ErrNoSource {
+		t.Errorf("StartOfLine, expected ErrNoSource got %v", next.Err)
+	}
+	if next := Text(AnyItem()).Run(state); next.Err != ErrNoSource {
+		t.Errorf("Text, expected ErrNoSource got %v", next.Err)
+	}
+}
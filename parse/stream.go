@@ -0,0 +1,65 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Stream is a sequence of items, such as the tokens from a lexer, that can be
+// parsed instead of the bytes of a Source. All of the combinators work on a stream,
+// as they only look at the Index; the parsers that read the bytes of a Source
+// (e.g. the ones in the match package) do not, and fail with ErrNoSource.
+type Stream interface {
+	// Len is the number of items in the stream
+	Len() int64
+	// At returns the item at index i, where 0 <= i < Len()
+	At(i int64) interface{}
+}
+
+// ErrNoSource is returned when reading bytes from a state that only has a Stream
+var ErrNoSource = errors.New("no byte source")
+
+// Slice is a Stream of the items in the slice
+type Slice []interface{}
+
+func (s Slice) Len() int64             { return int64(len(s)) }
+func (s Slice) At(i int64) interface{} { return s[i] }
+
+// NextItem returns the item at the current index of the stream
+// io.EOF is returned at the end of the stream
+func (state State) NextItem() (interface{}, error) {
+	if state.Stream == nil {
+		return nil, fmt.Errorf("no stream to read items from")
+	}
+	if state.Index < 0 || state.Index >= state.Stream.Len() {
+		return nil, io.EOF
+	}
+	return state.Stream.At(state.Index), nil
+}
+
+// Item matches one item from the stream that fn returns true for
+// result is the item
+func Item(fn func(item interface{}) bool, errVal error) Parser {
+	return Func(func(state State) State {
+		item, err := state.NextItem()
+		if err != nil || !fn(item) {
+			return state.WithError(errVal)
+		}
+		return state.WithResult(item, state.Index+1)
+	})
+}
+
+// AnyItem matches any one item from the stream
+// result is the item
+func AnyItem() Parser {
+	return Item(func(_ interface{}) bool { return true }, fmt.Errorf("expected an item"))
+}
+
+// Items will run the parser over the items of the stream
+func Items(parser Parser, stream Stream) State {
+	originalState := State{
+		Stream: stream,
+	}
+	return parser.Run(originalState)
+}
@@ -0,0 +1,68 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// UTF8Policy is what ReadNextRune does with bytes that are not valid UTF-8
+type UTF8Policy uint8
+
+const (
+	// UTF8Replace reads each invalid byte as utf8.RuneError (U+FFFD)
+	UTF8Replace UTF8Policy = iota
+	// UTF8Error fails with an *InvalidUTF8Error with the offset of the invalid byte.
+	// Many, Many1, ChoiceOf and Optional will not backtrack over this error, so it
+	// is reported where the invalid byte is instead of as a failure to match.
+	UTF8Error
+	// UTF8Raw reads each invalid byte as a rune in the range U+DC80 to U+DCFF, see RawByteRune.
+	// RunesToString will turn them back into the original bytes.
+	UTF8Raw
+)
+
+// InvalidUTF8Error is returned when the UTF8Error policy finds an invalid byte
+type InvalidUTF8Error struct {
+	Offset int64
+	Byte   byte
+}
+
+func (err *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("invalid UTF-8 byte %#02x at %v", err.Byte, err.Offset)
+}
+
+// IsInvalidUTF8 reports whether err is, or wraps, an *InvalidUTF8Error
+func IsInvalidUTF8(err error) bool {
+	var invalid *InvalidUTF8Error
+	return errors.As(err, &invalid)
+}
+
+// rawByteBase is where the raw bytes are mapped to, as unpaired low surrogates
+// they can not appear in valid UTF-8.
+const rawByteBase = 0xDC00
+
+// RawByteRune returns the rune that UTF8Raw uses for the invalid byte b
+func RawByteRune(b byte) rune { return rawByteBase + rune(b) }
+
+// RawByte returns the invalid byte r stands for, if r is from RawByteRune
+func RawByte(r rune) (byte, bool) {
+	if r < rawByteBase+0x80 || r > rawByteBase+0xFF {
+		return 0, false
+	}
+	return byte(r - rawByteBase), true
+}
+
+// RunesToString converts the runes into a string, like string(runes), but
+// the runes from RawByteRune are written as the original bytes.
+func RunesToString(runes []rune) string {
+	var str strings.Builder
+	str.Grow(len(runes))
+	for _, r := range runes {
+		if b, ok := RawByte(r); ok {
+			str.WriteByte(b)
+			continue
+		}
+		str.WriteRune(r)
+	}
+	return str.String()
+}